@@ -0,0 +1,150 @@
+package imagetools
+
+import (
+	"image/color"
+	"math"
+)
+
+// Metric of the difference between colors, of 0 for the same colors
+type ColorDistance interface {
+	Distance(x, y color.Color) float64
+}
+
+// Squared Euclidean distance in 16-bit RGBA, as [ColorDiff]
+type RGBADistance struct{}
+
+func (RGBADistance) Distance(x, y color.Color) float64 {
+	return float64(ColorDiff(x, y))
+}
+
+// ΔE*ab of 1976, the Euclidean distance in L*a*b* of the white point, D65 if zero.
+// The alpha is divided out and ignored.
+type DeltaE76 struct{ White WhitePoint }
+
+func (d DeltaE76) Distance(x, y color.Color) float64 {
+	w := d.White.orD65()
+	return CIE76(ToSpace(x, SpaceLab, w), ToSpace(y, SpaceLab, w))
+}
+
+// ΔE*94 in L*a*b* of the white point, D65 if zero, of the weights of graphic arts,
+// or of textiles if set, x being the reference. The alpha is divided out and ignored.
+type DeltaE94 struct {
+	White    WhitePoint
+	Textiles bool
+}
+
+func (d DeltaE94) Distance(x, y color.Color) float64 {
+	w := d.White.orD65()
+	return CIE94(ToSpace(x, SpaceLab, w), ToSpace(y, SpaceLab, w), d.Textiles)
+}
+
+// ΔE00 of CIEDE2000 in L*a*b* of the white point, D65 if zero, of the parametric factors
+// KL, KC and KH of lightness, chroma and hue, 1 if zero. The alpha is divided out and ignored.
+type DeltaE2000 struct {
+	White      WhitePoint
+	KL, KC, KH float64
+}
+
+func (d DeltaE2000) Distance(x, y color.Color) float64 {
+	w := d.White.orD65()
+	k := func(t float64) float64 {
+		if t == 0 {
+			return 1
+		}
+		return t
+	}
+	return CIEDE2000(ToSpace(x, SpaceLab, w), ToSpace(y, SpaceLab, w), k(d.KL), k(d.KC), k(d.KH))
+}
+
+// The white point, or D65 if zero
+func (w WhitePoint) orD65() WhitePoint {
+	if w == (WhitePoint{}) {
+		return D65
+	}
+	return w
+}
+
+// ΔE*ab of 1976 between colors in L*a*b*
+func CIE76(x, y [3]float64) float64 {
+	return math.Sqrt((x[0]-y[0])*(x[0]-y[0]) + (x[1]-y[1])*(x[1]-y[1]) + (x[2]-y[2])*(x[2]-y[2]))
+}
+
+// ΔE*94 between the reference x and y in L*a*b*, of the weights of graphic arts,
+// or of textiles if set
+func CIE94(x, y [3]float64, textiles bool) float64 {
+	kl, k1, k2 := 1.0, 0.045, 0.015
+	if textiles {
+		kl, k1, k2 = 2, 0.048, 0.014
+	}
+	c1, c2 := math.Hypot(x[1], x[2]), math.Hypot(y[1], y[2])
+	dl, dc := x[0]-y[0], c1-c2
+	da, db := x[1]-y[1], x[2]-y[2]
+	dh2 := max(da*da+db*db-dc*dc, 0)
+	sc, sh := 1+k1*c1, 1+k2*c1
+	return math.Sqrt((dl/kl)*(dl/kl) + (dc/sc)*(dc/sc) + dh2/(sh*sh))
+}
+
+// ΔE00 of CIEDE2000 between colors in L*a*b*, of the parametric factors
+// kl, kc and kh of lightness, chroma and hue, as in Sharma, Wu and Dalal (2005)
+func CIEDE2000(x, y [3]float64, kl, kc, kh float64) float64 {
+	const deg = math.Pi / 180
+	pow7 := func(t float64) float64 { return t * t * t * t * t * t * t }
+	cb := (math.Hypot(x[1], x[2]) + math.Hypot(y[1], y[2])) / 2
+	g := 0.5 * (1 - math.Sqrt(pow7(cb)/(pow7(cb)+pow7(25))))
+	a1, a2 := (1+g)*x[1], (1+g)*y[1]
+	c1, c2 := math.Hypot(a1, x[2]), math.Hypot(a2, y[2])
+	h := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		return math.Mod(math.Atan2(b, a)/deg+360, 360)
+	}
+	h1, h2 := h(x[2], a1), h(y[2], a2)
+
+	dl, dc, dh := y[0]-x[0], c2-c1, 0.0
+	if c1*c2 != 0 {
+		switch dh = h2 - h1; {
+		case dh > 180:
+			dh -= 360
+		case dh < -180:
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(dh/2*deg)
+
+	lm, cm, hm := (x[0]+y[0])/2, (c1+c2)/2, h1+h2
+	if c1*c2 != 0 {
+		switch {
+		case math.Abs(h1-h2) <= 180:
+			hm /= 2
+		case hm < 360:
+			hm = (hm + 360) / 2
+		default:
+			hm = (hm - 360) / 2
+		}
+	}
+	t := 1 - 0.17*math.Cos((hm-30)*deg) + 0.24*math.Cos(2*hm*deg) +
+		0.32*math.Cos((3*hm+6)*deg) - 0.20*math.Cos((4*hm-63)*deg)
+	dθ := 30 * math.Exp(-((hm-275)/25)*((hm-275)/25))
+	rc := 2 * math.Sqrt(pow7(cm)/(pow7(cm)+pow7(25)))
+	sl := 1 + 0.015*(lm-50)*(lm-50)/math.Sqrt(20+(lm-50)*(lm-50))
+	sc, sh := 1+0.045*cm, 1+0.015*cm*t
+	rt := -math.Sin(2*dθ*deg) * rc
+	l, c, hh := dl/(kl*sl), dc/(kc*sc), dH/(kh*sh)
+	return math.Sqrt(l*l + c*c + hh*hh + rt*c*hh)
+}
+
+// Index of the color of the palette closest to c by the metric d,
+// or by [color.Palette.Index] if d is nil
+func PaletteIndex(p color.Palette, c color.Color, d ColorDistance) int {
+	if d == nil {
+		return p.Index(c)
+	}
+	r, best := 0, math.Inf(1)
+	for i, v := range p {
+		if t := d.Distance(c, v); t < best {
+			r, best = i, t
+		}
+	}
+	return r
+}
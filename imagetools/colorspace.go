@@ -0,0 +1,369 @@
+package imagetools
+
+import (
+	"image/color"
+	"math"
+)
+
+// White point of the CIE XYZ tristimulus values, normalized to Y = 1
+type WhitePoint struct{ X, Y, Z float64 }
+
+// Standard illuminants of the CIE 1931 2° observer
+var (
+	D65         = WhitePoint{0.95047, 1, 1.08883} // daylight, the white of sRGB
+	D50         = WhitePoint{0.96422, 1, 0.82521} // horizon light, the white of ICC profiles
+	IlluminantA = WhitePoint{1.09850, 1, 0.35585} // incandescent light
+	IlluminantE = WhitePoint{1, 1, 1}             // equal energy
+)
+
+// White point of the chromaticity (x,y)
+func WhitePointXY(x, y float64) WhitePoint {
+	return WhitePoint{x / y, 1, (1 - x - y) / y}
+}
+
+// Color space of 3 channels
+type ColorSpace int
+
+const (
+	SpaceSRGB      ColorSpace = iota // gamma-encoded sRGB in [0,1]
+	SpaceLinearRGB                   // linear sRGB in [0,1]
+	SpaceXYZ                         // CIE XYZ of Y in [0,1], relative to the white point
+	SpaceLab                         // CIE L*a*b* of L* in [0,100], relative to the white point
+	SpaceLuv                         // CIE L*u*v* of L* in [0,100], relative to the white point
+	SpaceHSV                         // hue in degrees [0,360), saturation and value in [0,1], of sRGB
+	SpaceHSL                         // hue in degrees [0,360), saturation and lightness in [0,1], of sRGB
+	SpaceYCbCr                       // BT.601 full range as JPEG, Y in [0,1] and Cb, Cr in [-0.5,0.5], of sRGB
+)
+
+// Decode a channel of sRGB into linear light
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// Encode a channel of linear light into sRGB
+func LinearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// Product of a 3×3 matrix of rows and a vector
+func mul3(m *[3][3]float64, v [3]float64) (r [3]float64) {
+	for i, row := range m {
+		r[i] = row[0]*v[0] + row[1]*v[1] + row[2]*v[2]
+	}
+	return r
+}
+
+// Inverse of a 3×3 matrix by its adjugate
+func inv3(m *[3][3]float64) (r [3][3]float64) {
+	for i := range 3 {
+		for j := range 3 {
+			// Cofactor of m[j][i], the cyclic order of the rows and columns giving its sign
+			a, b := (j+1)%3, (j+2)%3
+			c, d := (i+1)%3, (i+2)%3
+			r[i][j] = m[a][c]*m[b][d] - m[a][d]*m[b][c]
+		}
+	}
+	det := m[0][0]*r[0][0] + m[0][1]*r[1][0] + m[0][2]*r[2][0]
+	for i := range r {
+		for j := range r[i] {
+			r[i][j] /= det
+		}
+	}
+	return r
+}
+
+var (
+	// Linear sRGB to XYZ of D65, and back by the exact inverse
+	rgbToXYZ = [3][3]float64{
+		{0.4124564, 0.3575761, 0.1804375},
+		{0.2126729, 0.7151522, 0.0721750},
+		{0.0193339, 0.1191920, 0.9503041},
+	}
+	xyzToRGB = inv3(&rgbToXYZ)
+	// XYZ to the cone responses of the Bradford transform, and back
+	bradford = [3][3]float64{
+		{0.8951, 0.2664, -0.1614},
+		{-0.7502, 1.7135, 0.0367},
+		{0.0389, -0.0685, 1.0296},
+	}
+	bradfordInv = inv3(&bradford)
+)
+
+// Adapt XYZ of white point from to white point to by the Bradford transform
+func AdaptXYZ(v [3]float64, from, to WhitePoint) [3]float64 {
+	if from == to {
+		return v
+	}
+	s, d := mul3(&bradford, [3]float64{from.X, from.Y, from.Z}), mul3(&bradford, [3]float64{to.X, to.Y, to.Z})
+	c := mul3(&bradford, v)
+	for i := range c {
+		c[i] *= d[i] / s[i]
+	}
+	return mul3(&bradfordInv, c)
+}
+
+// Constants of CIE L*: ε = (6/29)³ and κ = (29/3)³
+const (
+	labEpsilon = 216.0 / 24389
+	labKappa   = 24389.0 / 27
+)
+
+// Lightness L* of the relative luminance y
+func lightness(y float64) float64 {
+	if y > labEpsilon {
+		return 116*math.Cbrt(y) - 16
+	}
+	return labKappa * y
+}
+
+// Relative luminance of the lightness L*
+func luminance(l float64) float64 {
+	if l > labKappa*labEpsilon {
+		return math.Pow((l+16)/116, 3)
+	}
+	return l / labKappa
+}
+
+// XYZ to L*a*b* relative to the white point w
+func XYZToLab(v [3]float64, w WhitePoint) [3]float64 {
+	f := func(t float64) float64 {
+		if t > labEpsilon {
+			return math.Cbrt(t)
+		}
+		return (labKappa*t + 16) / 116
+	}
+	fx, fy, fz := f(v[0]/w.X), f(v[1]/w.Y), f(v[2]/w.Z)
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+// L*a*b* relative to the white point w to XYZ
+func LabToXYZ(v [3]float64, w WhitePoint) [3]float64 {
+	fy := (v[0] + 16) / 116
+	fx, fz := fy+v[1]/500, fy-v[2]/200
+	f := func(t float64) float64 {
+		if t3 := t * t * t; t3 > labEpsilon {
+			return t3
+		}
+		return (116*t - 16) / labKappa
+	}
+	return [3]float64{f(fx) * w.X, luminance(v[0]) * w.Y, f(fz) * w.Z}
+}
+
+// Chromaticity (u',v') of XYZ, of the white for black
+func uvPrime(v [3]float64, w WhitePoint) (u, v1 float64) {
+	d := v[0] + 15*v[1] + 3*v[2]
+	if d == 0 {
+		return uvPrime([3]float64{w.X, w.Y, w.Z}, w)
+	}
+	return 4 * v[0] / d, 9 * v[1] / d
+}
+
+// XYZ to L*u*v* relative to the white point w
+func XYZToLuv(v [3]float64, w WhitePoint) [3]float64 {
+	l := lightness(v[1] / w.Y)
+	u, v1 := uvPrime(v, w)
+	uw, vw := uvPrime([3]float64{w.X, w.Y, w.Z}, w)
+	return [3]float64{l, 13 * l * (u - uw), 13 * l * (v1 - vw)}
+}
+
+// L*u*v* relative to the white point w to XYZ
+func LuvToXYZ(v [3]float64, w WhitePoint) [3]float64 {
+	if v[0] <= 0 {
+		return [3]float64{}
+	}
+	uw, vw := uvPrime([3]float64{w.X, w.Y, w.Z}, w)
+	u, v1 := v[1]/(13*v[0])+uw, v[2]/(13*v[0])+vw
+	y := luminance(v[0]) * w.Y
+	return [3]float64{y * 9 * u / (4 * v1), y, y * (12 - 3*u - 20*v1) / (4 * v1)}
+}
+
+// Hue in degrees [0,360) and the extremes of RGB
+func hue(v [3]float64) (h, lo, hi float64) {
+	lo, hi = min(v[0], v[1], v[2]), max(v[0], v[1], v[2])
+	switch c := hi - lo; {
+	case c == 0:
+	case hi == v[0]:
+		h = math.Mod((v[1]-v[2])/c+6, 6)
+	case hi == v[1]:
+		h = (v[2]-v[0])/c + 2
+	default:
+		h = (v[0]-v[1])/c + 4
+	}
+	return 60 * h, lo, hi
+}
+
+// RGB of hue h in degrees, chroma c and the minimum m
+func fromHue(h, c, m float64) [3]float64 {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	var v [3]float64
+	switch int(h) {
+	case 0:
+		v = [3]float64{c, x, 0}
+	case 1:
+		v = [3]float64{x, c, 0}
+	case 2:
+		v = [3]float64{0, c, x}
+	case 3:
+		v = [3]float64{0, x, c}
+	case 4:
+		v = [3]float64{x, 0, c}
+	default:
+		v = [3]float64{c, 0, x}
+	}
+	return [3]float64{v[0] + m, v[1] + m, v[2] + m}
+}
+
+// RGB in [0,1] to HSV
+func RGBToHSV(v [3]float64) [3]float64 {
+	h, lo, hi := hue(v)
+	s := 0.0
+	if hi > 0 {
+		s = (hi - lo) / hi
+	}
+	return [3]float64{h, s, hi}
+}
+
+// HSV to RGB in [0,1]
+func HSVToRGB(v [3]float64) [3]float64 {
+	c := v[2] * v[1]
+	return fromHue(v[0], c, v[2]-c)
+}
+
+// RGB in [0,1] to HSL
+func RGBToHSL(v [3]float64) [3]float64 {
+	h, lo, hi := hue(v)
+	l, s := (hi+lo)/2, 0.0
+	if d := 1 - math.Abs(hi+lo-1); d > 0 {
+		s = (hi - lo) / d
+	}
+	return [3]float64{h, s, l}
+}
+
+// HSL to RGB in [0,1]
+func HSLToRGB(v [3]float64) [3]float64 {
+	c := (1 - math.Abs(2*v[2]-1)) * v[1]
+	return fromHue(v[0], c, v[2]-c/2)
+}
+
+// RGB in [0,1] to YCbCr of BT.601 full range
+func RGBToYCbCr(v [3]float64) [3]float64 {
+	return [3]float64{
+		0.299*v[0] + 0.587*v[1] + 0.114*v[2],
+		-0.168736*v[0] - 0.331264*v[1] + 0.5*v[2],
+		0.5*v[0] - 0.418688*v[1] - 0.081312*v[2],
+	}
+}
+
+// YCbCr of BT.601 full range to RGB in [0,1]
+func YCbCrToRGB(v [3]float64) [3]float64 {
+	return [3]float64{
+		v[0] + 1.402*v[2],
+		v[0] - 0.344136*v[1] - 0.714136*v[2],
+		v[0] + 1.772*v[1],
+	}
+}
+
+// Apply f to each of the channels
+func map3(v [3]float64, f func(float64) float64) [3]float64 {
+	return [3]float64{f(v[0]), f(v[1]), f(v[2])}
+}
+
+// Convert the channels of a color in space s of sRGB, without decoding, into sRGB,
+// or false for the other spaces
+func toSRGB(v [3]float64, s ColorSpace) ([3]float64, bool) {
+	switch s {
+	case SpaceSRGB:
+		return v, true
+	case SpaceHSV:
+		return HSVToRGB(v), true
+	case SpaceHSL:
+		return HSLToRGB(v), true
+	case SpaceYCbCr:
+		return YCbCrToRGB(v), true
+	}
+	return v, false
+}
+
+// Convert the channels of a color in sRGB into space s of sRGB, without encoding,
+// or false for the other spaces
+func fromSRGB(v [3]float64, s ColorSpace) ([3]float64, bool) {
+	switch s {
+	case SpaceSRGB:
+		return v, true
+	case SpaceHSV:
+		return RGBToHSV(v), true
+	case SpaceHSL:
+		return RGBToHSL(v), true
+	case SpaceYCbCr:
+		return RGBToYCbCr(v), true
+	}
+	return v, false
+}
+
+// Convert the channels of a color in space s of white point w into linear sRGB
+func toLinearRGB(v [3]float64, s ColorSpace, w WhitePoint) [3]float64 {
+	switch s {
+	case SpaceXYZ:
+		return mul3(&xyzToRGB, AdaptXYZ(v, w, D65))
+	case SpaceLab:
+		return toLinearRGB(LabToXYZ(v, w), SpaceXYZ, w)
+	case SpaceLuv:
+		return toLinearRGB(LuvToXYZ(v, w), SpaceXYZ, w)
+	}
+	if v, ok := toSRGB(v, s); ok {
+		return map3(v, SRGBToLinear)
+	}
+	return v
+}
+
+// Convert the channels of a color in linear sRGB into space s of white point w
+func fromLinearRGB(v [3]float64, s ColorSpace, w WhitePoint) [3]float64 {
+	switch s {
+	case SpaceXYZ:
+		return AdaptXYZ(mul3(&rgbToXYZ, v), D65, w)
+	case SpaceLab:
+		return XYZToLab(fromLinearRGB(v, SpaceXYZ, w), w)
+	case SpaceLuv:
+		return XYZToLuv(fromLinearRGB(v, SpaceXYZ, w), w)
+	}
+	if r, ok := fromSRGB(map3(v, LinearToSRGB), s); ok {
+		return r
+	}
+	return v
+}
+
+// Convert the channels of a color from space from into space to, of white point w.
+// sRGB is of the white point D65, to which XYZ, L*a*b* and L*u*v* of other white points
+// are adapted by the Bradford transform. The spaces of sRGB, as HSV, are converted
+// among themselves without decoding into linear light.
+func ConvertColor(v [3]float64, from, to ColorSpace, w WhitePoint) [3]float64 {
+	if from == to {
+		return v
+	} else if u, ok := toSRGB(v, from); ok {
+		if r, ok := fromSRGB(u, to); ok {
+			return r
+		}
+	}
+	return fromLinearRGB(toLinearRGB(v, from, w), to, w)
+}
+
+// Channels of a color in space s of white point w, of the alpha divided out
+func ToSpace(c color.Color, s ColorSpace, w WhitePoint) [3]float64 {
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	v := [3]float64{float64(n.R) / 0xffff, float64(n.G) / 0xffff, float64(n.B) / 0xffff}
+	return ConvertColor(v, SpaceSRGB, s, w)
+}
+
+// Opaque color of the channels in space s of white point w, clamped into the gamut of sRGB
+func FromSpace(v [3]float64, s ColorSpace, w WhitePoint) color.NRGBA64 {
+	v = ConvertColor(v, s, SpaceSRGB, w)
+	c := func(t float64) uint16 { return uint16(math.Round(min(max(t, 0), 1) * 0xffff)) }
+	return color.NRGBA64{c(v[0]), c(v[1]), c(v[2]), 0xffff}
+}
@@ -0,0 +1,303 @@
+package imagetools
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// Algorithm of dithering
+type DitherMethod int
+
+const (
+	DitherNone           DitherMethod = iota // the nearest colors
+	DitherFloydSteinberg                     // error diffusion to 4 neighbors
+	DitherJJN                                // error diffusion of Jarvis, Judice and Ninke to 12 neighbors
+	DitherStucki                             // error diffusion of Stucki to 12 neighbors
+	DitherAtkinson                           // error diffusion of 3/4 of the error to 6 neighbors
+	DitherSierra                             // error diffusion of Sierra to 10 neighbors
+	DitherBayer                              // ordered by the Bayer matrix
+	DitherBlueNoise                          // ordered by a blue noise matrix of void-and-cluster
+)
+
+// Weight of the error diffused to the pixel (dx,dy) from the current one
+type diffusion struct{ dx, dy, w int }
+
+// Error diffusion kernels, of the weights of sum the divisor but for Atkinson
+var diffusions = map[DitherMethod]struct {
+	d  []diffusion
+	by int
+}{
+	DitherFloydSteinberg: {[]diffusion{{1, 0, 7}, {-1, 1, 3}, {0, 1, 5}, {1, 1, 1}}, 16},
+	DitherJJN: {[]diffusion{
+		{1, 0, 7}, {2, 0, 5},
+		{-2, 1, 3}, {-1, 1, 5}, {0, 1, 7}, {1, 1, 5}, {2, 1, 3},
+		{-2, 2, 1}, {-1, 2, 3}, {0, 2, 5}, {1, 2, 3}, {2, 2, 1},
+	}, 48},
+	DitherStucki: {[]diffusion{
+		{1, 0, 8}, {2, 0, 4},
+		{-2, 1, 2}, {-1, 1, 4}, {0, 1, 8}, {1, 1, 4}, {2, 1, 2},
+		{-2, 2, 1}, {-1, 2, 2}, {0, 2, 4}, {1, 2, 2}, {2, 2, 1},
+	}, 42},
+	DitherAtkinson: {[]diffusion{{1, 0, 1}, {2, 0, 1}, {-1, 1, 1}, {0, 1, 1}, {1, 1, 1}, {0, 2, 1}}, 8},
+	DitherSierra: {[]diffusion{
+		{1, 0, 5}, {2, 0, 3},
+		{-2, 1, 2}, {-1, 1, 4}, {0, 1, 5}, {1, 1, 4}, {2, 1, 2},
+		{-1, 2, 2}, {0, 2, 3}, {1, 2, 2},
+	}, 32},
+}
+
+// Options of dithering
+type DitherOptions struct {
+	Method     DitherMethod
+	Serpentine bool          // scanning the rows of error diffusion in alternating directions
+	Size       int           // side of the ordered dithering matrix, a power of 2, 8 or 32 of blue noise if non-positive
+	Strength   float64       // scale of the ordered dithering, 1 if zero
+	Metric     ColorDistance // of the nearest colors, the k-d tree of RGBA if nil
+}
+
+// Dither an image into the palette
+func Dither(m image.Image, p color.Palette, o DitherOptions) *image.Paletted {
+	r := image.NewPaletted(m.Bounds(), p)
+	o.Draw(r, r.Rect, m, r.Rect.Min)
+	return r
+}
+
+// Draw src at sp into r of dst dithered into the palette of dst,
+// as a [draw.Drawer] such as the Drawer of [gif.Options].
+// Images of no palette are drawn by [draw.Src].
+func (o DitherOptions) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	pd, ok := dst.(*image.Paletted)
+	if !ok || len(pd.Palette) == 0 {
+		draw.Draw(dst, r, src, sp, draw.Src)
+		return
+	}
+	// Clip as by draw.Draw
+	r = r.Intersect(dst.Bounds())
+	sr := src.Bounds().Intersect(r.Sub(r.Min).Add(sp))
+	r = sr.Sub(sp).Add(r.Min)
+	if r.Empty() {
+		return
+	}
+
+	p := pd.Palette
+	tree := NewPaletteTree(p)
+	index := func(v [4]float64) int {
+		if o.Metric != nil {
+			return PaletteIndex(p, meanColor(v, 1), o.Metric)
+		}
+		return tree.tree.nearest(v)
+	}
+	pal := make([][4]float64, len(p))
+	for i, c := range p {
+		r, g, b, a := c.RGBA()
+		pal[i] = [4]float64{float64(r), float64(g), float64(b), float64(a)}
+	}
+	clamp := func(v [4]float64) [4]float64 {
+		v[3] = min(max(v[3], 0), 0xffff)
+		for k := range 3 {
+			v[k] = min(max(v[k], 0), v[3])
+		}
+		return v
+	}
+	at := func(x, y int) [4]float64 {
+		return rgba64Vector(Pixel(src, sp.X+x-r.Min.X, sp.Y+y-r.Min.Y))
+	}
+
+	switch o.Method {
+	case DitherBayer, DitherBlueNoise:
+		n := o.Size
+		switch {
+		case n > 0:
+		case o.Method == DitherBayer:
+			n = 8
+		default:
+			n = 32
+		}
+		n = 1 << bits.Len(uint(n-1)) // a power of 2
+		t := BayerMatrix(n)
+		if o.Method == DitherBlueNoise {
+			t = BlueNoiseMatrix(n)
+		}
+		s := o.Strength
+		if s == 0 {
+			s = 1
+		}
+		s *= paletteSpacing(pal)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				v := at(x, y)
+				d := s * (t[(y&(n-1))*n+x&(n-1)] - 0.5) * v[3] / 0xffff
+				for k := range 3 {
+					v[k] += d
+				}
+				pd.Pix[pd.PixOffset(x, y)] = uint8(index(clamp(v)))
+			}
+		}
+
+	default:
+		k, ok := diffusions[o.Method]
+		// Errors of the current and next 2 rows, of 2 columns of margin each side
+		w := r.Dx() + 4
+		var e [3][][4]float64
+		for i := range e {
+			e[i] = make([][4]float64, w)
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			dir, x0, x1 := 1, r.Min.X, r.Max.X
+			if o.Serpentine && (y-r.Min.Y)%2 == 1 {
+				dir, x0, x1 = -1, r.Max.X-1, r.Min.X-1
+			}
+			for x := x0; x != x1; x += dir {
+				v := at(x, y)
+				i := x - r.Min.X + 2
+				for c := range v {
+					v[c] += e[0][i][c]
+				}
+				j := index(clamp(v))
+				pd.Pix[pd.PixOffset(x, y)] = uint8(j)
+				if !ok {
+					continue
+				}
+				for _, d := range k.d {
+					f := float64(d.w) / float64(k.by)
+					for c := range v {
+						e[d.dy][i+d.dx*dir][c] += f * (v[c] - pal[j][c])
+					}
+				}
+			}
+			e[0], e[1], e[2] = e[1], e[2], e[0]
+			clear(e[2])
+		}
+	}
+}
+
+// Mean spacing of the colors of a palette, the largest difference of RGB
+// of each color and its nearest other one, of black and white 0xffff
+func paletteSpacing(pal [][4]float64) float64 {
+	s, n := 0.0, 0
+	for i, c := range pal {
+		d := math.Inf(1)
+		for j, c1 := range pal {
+			e := max(math.Abs(c[0]-c1[0]), math.Abs(c[1]-c1[1]), math.Abs(c[2]-c1[2]))
+			if j != i && e > 0 {
+				d = min(d, e)
+			}
+		}
+		if !math.IsInf(d, 1) {
+			s += d
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return s / float64(n)
+}
+
+// Bayer matrix of n*n thresholds in (0,1), n being a power of 2, row-major,
+// of each threshold spread as far from the previous ones as possible
+func BayerMatrix(n int) []float64 {
+	m := []int{0}
+	for s := 1; s < n; s *= 2 {
+		m1 := make([]int, 4*s*s)
+		for y := range s {
+			for x := range s {
+				v := 4 * m[y*s+x]
+				m1[y*2*s+x] = v
+				m1[y*2*s+x+s] = v + 2
+				m1[(y+s)*2*s+x] = v + 3
+				m1[(y+s)*2*s+x+s] = v + 1
+			}
+		}
+		m = m1
+	}
+	t := make([]float64, len(m))
+	for i, v := range m {
+		t[i] = (float64(v) + 0.5) / float64(len(m))
+	}
+	return t
+}
+
+// Blue noise matrix of n*n thresholds in (0,1), row-major, tiling seamlessly,
+// by the void-and-cluster method of Ulichney of the Gaussian filter of sigma 1.5.
+// The matrix is the same for the same n.
+func BlueNoiseMatrix(n int) []float64 {
+	if n <= 0 {
+		return nil
+	}
+	const sigma = 1.5
+	size := n * n
+	// Gaussian of the toroidal distances
+	g := make([]float64, size)
+	for i := range g {
+		dx, dy := min(i%n, n-i%n), min(i/n, n-i/n)
+		g[i] = math.Exp(-float64(dx*dx+dy*dy) / (2 * sigma * sigma))
+	}
+	on := make([]bool, size)
+	energy := make([]float64, size)
+	set := func(i int, v bool) {
+		on[i] = v
+		s := 1.0
+		if !v {
+			s = -1
+		}
+		x, y := i%n, i/n
+		for j := range energy {
+			energy[j] += s * g[((j/n-y+n)%n)*n+(j%n-x+n)%n]
+		}
+	}
+	// The tightest cluster of the pixels on, or the largest void of those off
+	extreme := func(v bool) int {
+		k := -1
+		for i, e := range energy {
+			if on[i] == v && (k < 0 || v && e > energy[k] || !v && e < energy[k]) {
+				k = i
+			}
+		}
+		return k
+	}
+
+	// Initial pattern of a tenth of the pixels, relaxed by moving the tightest cluster
+	// into the largest void until they coincide
+	rng := rand.New(rand.NewSource(int64(n)))
+	ones := max(size/10, 1)
+	for _, i := range rng.Perm(size)[:ones] {
+		set(i, true)
+	}
+	for range size {
+		c := extreme(true)
+		set(c, false)
+		v := extreme(false)
+		set(v, true)
+		if v == c {
+			break
+		}
+	}
+	initial := append([]bool(nil), on...)
+	initialEnergy := append([]float64(nil), energy...)
+
+	rank := make([]int, size)
+	// Ranks below the initial pattern, removing the tightest clusters
+	for r := ones - 1; r >= 0; r-- {
+		c := extreme(true)
+		set(c, false)
+		rank[c] = r
+	}
+	// Ranks above, filling the largest voids
+	copy(on, initial)
+	copy(energy, initialEnergy)
+	for r := ones; r < size; r++ {
+		v := extreme(false)
+		set(v, true)
+		rank[v] = r
+	}
+	t := make([]float64, size)
+	for i, r := range rank {
+		t[i] = (float64(r) + 0.5) / float64(size)
+	}
+	return t
+}
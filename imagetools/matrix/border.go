@@ -0,0 +1,143 @@
+package matrix
+
+import types "imagetools/types"
+
+// Extrapolation mode of the elements beyond the borders of a matrix,
+// illustrated with a row abcd as in "beyond|abcd|beyond"
+type Padding int
+
+const (
+	PadZero       Padding = iota // 0000|abcd|0000
+	PadConstant                  // kkkk|abcd|kkkk, k being Border.Value
+	PadReplicate                 // aaaa|abcd|dddd
+	PadReflect                   // dcba|abcd|dcba
+	PadReflect101                // dcb|abcd|cba
+	PadWrap                      // abcd|abcd|abcd
+)
+
+// Border handling of a matrix
+type Border[T types.Number] struct {
+	Mode  Padding
+	Value T // the constant filled by PadConstant
+}
+
+// Output size of a convolution
+type ConvSize int
+
+const (
+	ConvValid ConvSize = iota // only where the kernel lies entirely inside the matrix
+	ConvSame                  // the same size as the matrix, with the kernel centered
+	ConvFull                  // wherever the kernel overlaps the matrix
+)
+
+// Map index i of a row or column of length n into [0,n),
+// or -1 if the element is a constant
+func (b Border[T]) source(i, n int) int {
+	if i >= 0 && i < n {
+		return i
+	} else if n <= 0 {
+		return -1
+	}
+	mod := func(i, n int) int {
+		if i %= n; i < 0 {
+			i += n
+		}
+		return i
+	}
+	switch b.Mode {
+	case PadReplicate:
+		return min(max(i, 0), n-1)
+	case PadReflect:
+		if i = mod(i, 2*n); i >= n {
+			i = 2*n - 1 - i
+		}
+		return i
+	case PadReflect101:
+		if n == 1 {
+			return 0
+		} else if i = mod(i, 2*n-2); i >= n {
+			i = 2*n - 2 - i
+		}
+		return i
+	case PadWrap:
+		return mod(i, n)
+	default:
+		return -1
+	}
+}
+
+// Pad a matrix by the given margins into a new matrix, extrapolating by border.
+// Negative margins crop the matrix instead.
+func (m General[T]) Pad(left, top, right, bottom int, border Border[T]) General[T] {
+	r := NewGeneral[T](m.x+left+right, m.y+top+bottom)
+	if r.Empty() {
+		return r
+	}
+	m.reval()
+	c := border.Value
+	if border.Mode == PadZero {
+		c = 0
+	}
+	xs := make([]int, r.x)
+	for i := range xs {
+		xs[i] = border.source(i-left, m.x)
+	}
+	for j := range r.y {
+		row, y := r.val[j*r.x:(j+1)*r.x], border.source(j-top, m.y)
+		for i, x := range xs {
+			if x < 0 || y < 0 {
+				row[i] = c
+			} else {
+				row[i] = m.val[y*m.x+x]
+			}
+		}
+	}
+	return r
+}
+
+// Expand (or crop) a matrix with borders extrapolated by border
+//
+//	 dir:
+//		0 1 2
+//		3 4 5
+//		6 7 8
+func (m *General[T]) ExpandPad(x, y int, dir int, border Border[T]) *General[T] {
+	if dir >= 9 || dir < 0 {
+		dir = 0
+	}
+	dx := [3]int{0, (x - m.x) / 2, x - m.x}[dir%3]
+	dy := [3]int{0, (y - m.y) / 2, y - m.y}[dir/3]
+	*m = m.Pad(dx, dy, x-m.x-dx, y-m.y-dy, border)
+	return m
+}
+
+// Convolution with steps (dx,dy), of output size selected by size,
+// with the elements beyond the borders extrapolated by border.
+func (m General[T]) ConvPad(k Matrix[T], border Border[T], size ConvSize, dx, dy int) (*General[T], error) {
+	kernel := ViewOf(k)
+	if m.Empty() || kernel.Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	return m.padConv(kernel.Dims(), border, size).Conv(kernel, dx, dy)
+}
+
+// Pad a matrix so that the valid convolution with a kernel of dimensions d
+// results in the output size selected by size
+func (m General[T]) padConv(d Index2, border Border[T], size ConvSize) General[T] {
+	switch size {
+	case ConvSame:
+		return m.Pad(d[0]/2, d[1]/2, (d[0]-1)-d[0]/2, (d[1]-1)-d[1]/2, border)
+	case ConvFull:
+		return m.Pad(d[0]-1, d[1]-1, d[0]-1, d[1]-1, border)
+	}
+	return m
+}
+
+// Filter, without changing size, with the elements beyond the borders extrapolated by border
+func (m General[T]) FilterPad(k Matrix[T], border Border[T]) (General[T], error) {
+	r, err := m.ConvPad(k, border, ConvSame, 1, 1)
+	if r == nil {
+		return General[T]{}, err
+	}
+	return *r, err
+}
@@ -0,0 +1,153 @@
+package matrix
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Options of the contrast-limited adaptive histogram equalization
+type CLAHEOptions struct {
+	Tiles     Index2  // grid of tiles along x and y, 8*8 if zero, at most the dimensions
+	ClipLimit float64 // of the bins in multiples of the mean count of a bin, 2 if zero, unlimited if negative
+	Bins      int     // of the histograms of the tiles, 256 if non-positive
+}
+
+// Contrast-limited adaptive histogram equalization of Zuiderveld of the values of v in [0, hi],
+// each tile equalized by the CDF of its histogram of the bins clipped and the excess
+// redistributed evenly, and each element interpolated bilinearly between the mappings
+// of the 4 nearest tiles, linearly within the bins
+func clahe(v General[float64], hi float64, o CLAHEOptions) General[float64] {
+	tx, ty := o.Tiles[0], o.Tiles[1]
+	if tx <= 0 || ty <= 0 {
+		tx, ty = 8, 8
+	}
+	tx, ty = min(tx, v.x), min(ty, v.y)
+	clip := o.ClipLimit
+	if clip == 0 {
+		clip = 2
+	}
+	bins := o.Bins
+	if bins <= 0 {
+		bins = 256
+	}
+
+	// The edges of the tiles and the interpolation between their centers of each row or column
+	type lerp struct {
+		i int
+		w float64
+	}
+	lerps := func(n, t int) ([]int, []lerp) {
+		e := make([]int, t+1)
+		for i := range e {
+			e[i] = i * n / t
+		}
+		l := make([]lerp, n)
+		i := 0
+		for k := range l {
+			for i < t-1 && float64(k) >= float64(e[i+1]+e[i+2]-1)/2 {
+				i++
+			}
+			c0 := float64(e[i]+e[i+1]-1) / 2
+			if float64(k) < c0 || i == t-1 {
+				l[k] = lerp{i, 0}
+				continue
+			}
+			c1 := float64(e[i+1]+e[i+2]-1) / 2
+			l[k] = lerp{i, (float64(k) - c0) / (c1 - c0)}
+		}
+		return e, l
+	}
+	ex, lx := lerps(v.x, tx)
+	ey, ly := lerps(v.y, ty)
+
+	hs := make([]Histogram, tx*ty)
+	cdfs := make([][]float64, tx*ty)
+	for j := range ty {
+		for i := range tx {
+			h, _ := NewHistogram([]int{bins}, []float64{0}, []float64{hi + 1})
+			for y := ey[j]; y < ey[j+1]; y++ {
+				for _, t := range v.val[y*v.x+ex[i] : y*v.x+ex[i+1]] {
+					h.Add(1, t)
+				}
+			}
+			if clip > 0 {
+				c := h.Counts.val
+				limit := max(clip*h.Total()/float64(bins), 1)
+				excess := 0.0
+				for k, t := range c {
+					if t > limit {
+						excess += t - limit
+						c[k] = limit
+					}
+				}
+				for k := range c {
+					c[k] += excess / float64(bins)
+				}
+			}
+			hs[j*tx+i], cdfs[j*tx+i] = h, h.CDF()
+		}
+	}
+	// The mapping of t by the tile k, the values of a bin spread evenly over its CDF
+	mapping := func(k int, t float64) float64 {
+		h, c := hs[k], cdfs[k]
+		b := h.bin(0, t)
+		if b < 0 {
+			return t
+		}
+		lo := 0.0
+		if b > 0 {
+			lo = c[b-1]
+		}
+		w := h.Edge(0, 1) - h.Edge(0, 0)
+		f := min((t-h.Edge(0, b)+1)/w, 1)
+		return hi * (lo + f*(c[b]-lo))
+	}
+
+	r := NewGeneral[float64](v.x, v.y)
+	for y, ly := range ly {
+		j1 := min(ly.i+1, ty-1)
+		for x, lx := range lx {
+			i1 := min(lx.i+1, tx-1)
+			t := v.val[y*v.x+x]
+			top := (1-lx.w)*mapping(ly.i*tx+lx.i, t) + lx.w*mapping(ly.i*tx+i1, t)
+			bottom := (1-lx.w)*mapping(j1*tx+lx.i, t) + lx.w*mapping(j1*tx+i1, t)
+			r.val[y*v.x+x] = (1-ly.w)*top + ly.w*bottom
+		}
+	}
+	return r
+}
+
+// Contrast-limited adaptive histogram equalization of a matrix of 8 or 16 bits,
+// over the whole range of the type, against the noise amplified by global equalization
+func CLAHE[T ~uint8 | ~uint16](m Matrix[T], o CLAHEOptions) (General[T], error) {
+	g := float64Matrix(m)
+	if g.Empty() {
+		return General[T]{}, ErrEmptyMatrix
+	}
+	lo, hi := integerRange[T]()
+	r := clahe(g, float64(hi), o)
+	return MapMatrix[T](r, func(t float64) T { return saturate(t, lo, hi) }), nil
+}
+
+// Contrast-limited adaptive histogram equalization of the lightness L* of an image in
+// L*a*b* of D65 as [CLAHE] in 16 bits, of the hue and chroma of a* and b* and the alpha preserved
+func CLAHEImage(m image.Image, o CLAHEOptions) (*image.NRGBA64, error) {
+	if m == nil || m.Bounds().Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	const scale = 0xffff / 100.0
+	p := ImageToSpace(m, SpaceLab, D65)
+	l := MapMatrix(p[0], func(t float64) float64 { return min(max(math.Round(t*scale), 0), 0xffff) })
+	l = clahe(l, 0xffff, o)
+
+	b := m.Bounds()
+	r := image.NewNRGBA64(b)
+	for i, t := range l.val {
+		x, y := b.Min.X+i%b.Dx(), b.Min.Y+i/b.Dx()
+		c := FromSpace([3]float64{t / scale, p[1].val[i], p[2].val[i]}, SpaceLab, D65)
+		c.A = color.NRGBA64Model.Convert(m.At(x, y)).(color.NRGBA64).A
+		r.SetNRGBA64(x, y, c)
+	}
+	return r, nil
+}
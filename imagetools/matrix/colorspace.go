@@ -0,0 +1,73 @@
+package matrix
+
+import (
+	"image"
+	"image/color"
+)
+
+// Channels of an image in space s of white point w, as by [ToSpace],
+// of the alpha divided out, each a matrix of the dimensions of the image
+func ImageToSpace(m image.Image, s ColorSpace, w WhitePoint) [3]General[float64] {
+	var p [3]General[float64]
+	if m == nil {
+		return p
+	}
+	b := m.Bounds()
+	for k := range p {
+		p[k] = NewGeneral[float64](b.Dx(), b.Dy())
+	}
+	for i := range p[0].val {
+		c := Pixel(m, b.Min.X+i%b.Dx(), b.Min.Y+i/b.Dx())
+		var v [3]float64
+		if c.A > 0 {
+			a := float64(c.A)
+			v = ConvertColor([3]float64{float64(c.R) / a, float64(c.G) / a, float64(c.B) / a}, SpaceSRGB, s, w)
+		} else {
+			v = ConvertColor(v, SpaceSRGB, s, w)
+		}
+		p[0].val[i], p[1].val[i], p[2].val[i] = v[0], v[1], v[2]
+	}
+	return p
+}
+
+// Check that the channels are of the same dimensions
+func checkPlanes(op string, p [3]General[float64]) error {
+	if p[0].Dims() != p[1].Dims() || p[0].Dims() != p[2].Dims() {
+		return &DimensionError{
+			Op:   op,
+			Dims: []Index2{p[0].Dims(), p[1].Dims(), p[2].Dims()},
+			Why:  ErrDimensions,
+		}
+	}
+	return nil
+}
+
+// Convert the channels of a matrix from space from into space to, of white point w,
+// as by [ConvertColor], into new matrices
+func ConvertSpace(p [3]General[float64], from, to ColorSpace, w WhitePoint) ([3]General[float64], error) {
+	if err := checkPlanes("ConvertSpace", p); err != nil {
+		return p, err
+	}
+	var r [3]General[float64]
+	for k := range r {
+		r[k] = NewGeneral[float64](p[0].x, p[0].y)
+	}
+	for i := range r[0].val {
+		v := ConvertColor([3]float64{p[0].val[i], p[1].val[i], p[2].val[i]}, from, to, w)
+		r[0].val[i], r[1].val[i], r[2].val[i] = v[0], v[1], v[2]
+	}
+	return r, nil
+}
+
+// Opaque image of the channels in space s of white point w, as by [FromSpace]
+func SpaceToImage(p [3]General[float64], s ColorSpace, w WhitePoint) (*image.NRGBA64, error) {
+	if err := checkPlanes("SpaceToImage", p); err != nil {
+		return nil, err
+	}
+	m := image.NewNRGBA64(image.Rect(0, 0, p[0].x, p[0].y))
+	for i := range p[0].val {
+		c := FromSpace([3]float64{p[0].val[i], p[1].val[i], p[2].val[i]}, s, w)
+		m.SetNRGBA64(i%p[0].x, i/p[0].x, color.NRGBA64(c))
+	}
+	return m, nil
+}
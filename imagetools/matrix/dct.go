@@ -0,0 +1,340 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/cmplx"
+)
+
+// Orthonormal DCT-II of length n, X[k] = s(k) Σ x[j] cos(π(2j+1)k/2n),
+// s(0) = sqrt(1/n) and s(k) = sqrt(2/n), computed by an FFT of the same length.
+// Its inverse is the orthonormal DCT-III.
+type dctPlan struct {
+	fft *FFTPlan
+	w   []complex128 // s(k) exp(-iπk/2n)
+}
+
+func newDCTPlan(n int) (*dctPlan, error) {
+	f, err := NewFFTPlan(n)
+	if err != nil {
+		return nil, err
+	}
+	p := &dctPlan{fft: f, w: make([]complex128, n)}
+	for k := range p.w {
+		s, c := math.Sincos(-math.Pi * float64(k) / float64(2*n))
+		p.w[k] = complex(c, s) * complex(math.Sqrt(2/float64(n)), 0)
+	}
+	p.w[0] = complex(math.Sqrt(1/float64(n)), 0)
+	return p, nil
+}
+
+// DCT-II of src into dst, both of length n.
+// The even elements followed by the odd ones reversed have the DCT as the real part
+// of their DFT, twiddled.
+func (p *dctPlan) forward(dst, src []float64) {
+	n := len(p.w)
+	v, c := make([]float64, n), make([]complex128, n/2+1)
+	for k := 0; 2*k < n; k++ {
+		v[k] = src[2*k]
+	}
+	for k := 0; 2*k+1 < n; k++ {
+		v[n-1-k] = src[2*k+1]
+	}
+	p.fft.real(c, v)
+	for k, w := range p.w {
+		if k < len(c) {
+			dst[k] = real(c[k] * w)
+		} else {
+			dst[k] = real(cmplx.Conj(c[n-k]) * w)
+		}
+	}
+}
+
+// DCT-III of src into dst, both of length n, the inverse of forward
+func (p *dctPlan) inverse(dst, src []float64) {
+	n := len(p.w)
+	c, v := make([]complex128, n/2+1), make([]float64, n)
+	for k := range c {
+		// X[k] - iX[n-k] untwiddled, X[n] being 0
+		z := complex(src[k], 0)
+		if k > 0 {
+			z -= complex(0, src[n-k])
+		}
+		c[k] = z / p.w[k]
+	}
+	p.fft.realInverse(v, c)
+	for k := 0; 2*k < n; k++ {
+		dst[2*k] = v[k] / float64(n)
+	}
+	for k := 0; 2*k+1 < n; k++ {
+		dst[2*k+1] = v[n-1-k] / float64(n)
+	}
+}
+
+// Transform each row of m, then each column if cols, into a new matrix
+func dctApply(m Matrix[float64], cols, inverse bool) (General[float64], error) {
+	r := ViewOf(m).Clone()
+	px, err := newDCTPlan(r.x)
+	if err != nil {
+		return General[float64]{}, err
+	}
+	f := px.forward
+	if inverse {
+		f = px.inverse
+	}
+	t := make([]float64, max(r.x, r.y))
+	for y := range r.y {
+		row := r.val[y*r.x : (y+1)*r.x]
+		copy(t, row)
+		f(row, t[:r.x])
+	}
+	if !cols {
+		return r, nil
+	}
+	py, _ := newDCTPlan(r.y)
+	if f = py.forward; inverse {
+		f = py.inverse
+	}
+	c := make([]float64, r.y)
+	for x := range r.x {
+		for y := range c {
+			c[y] = r.val[y*r.x+x]
+		}
+		f(t[:r.y], c)
+		for y, v := range t[:r.y] {
+			r.val[y*r.x+x] = v
+		}
+	}
+	return r, nil
+}
+
+// Orthonormal 1-D DCT-II of each row of a matrix
+func DCT(m Matrix[float64]) (General[float64], error) {
+	return dctApply(m, false, false)
+}
+
+// Orthonormal 1-D DCT-III of each row of a matrix, the inverse of [DCT]
+func IDCT(m Matrix[float64]) (General[float64], error) {
+	return dctApply(m, false, true)
+}
+
+// Orthonormal 2-D DCT-II
+func DCT2(m Matrix[float64]) (General[float64], error) {
+	return dctApply(m, true, false)
+}
+
+// Orthonormal 2-D DCT-III, the inverse of [DCT2]
+func IDCT2(m Matrix[float64]) (General[float64], error) {
+	return dctApply(m, true, true)
+}
+
+// Side of the blocks of a block DCT
+const DCTBlock = 8
+
+// Quantization table of the coefficients of an 8*8 block DCT, in natural order
+type QuantTable [DCTBlock * DCTBlock]uint8
+
+// The example tables of the JPEG standard (ITU T.81 Annex K), of quality 50
+var (
+	QuantLuminance = QuantTable{
+		16, 11, 10, 16, 24, 40, 51, 61,
+		12, 12, 14, 19, 26, 58, 60, 55,
+		14, 13, 16, 24, 40, 57, 69, 56,
+		14, 17, 22, 29, 51, 87, 80, 62,
+		18, 22, 37, 56, 68, 109, 103, 77,
+		24, 35, 55, 64, 81, 104, 113, 92,
+		49, 64, 78, 87, 103, 121, 120, 101,
+		72, 92, 95, 98, 112, 100, 103, 99,
+	}
+	QuantChrominance = QuantTable{
+		17, 18, 24, 47, 99, 99, 99, 99,
+		18, 21, 26, 66, 99, 99, 99, 99,
+		24, 26, 56, 99, 99, 99, 99, 99,
+		47, 66, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	}
+)
+
+// Scale a quantization table to quality in [1,100], as the encoder of image/jpeg,
+// so that the tables match those of WriteJPEG of the same quality
+func (q QuantTable) Scale(quality int) QuantTable {
+	quality = min(max(quality, 1), 100)
+	scale := 200 - 2*quality
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	for i, t := range q {
+		q[i] = uint8(min(max((int(t)*scale+50)/100, 1), 255))
+	}
+	return q
+}
+
+// Orthonormal DCT-II matrix of size 8, C[k][j] = s(k) cos(π(2j+1)k/16)
+var dctBlockMatrix = func() (c [DCTBlock * DCTBlock]float64) {
+	for k := range DCTBlock {
+		s := math.Sqrt(2.0 / DCTBlock)
+		if k == 0 {
+			s = math.Sqrt(1.0 / DCTBlock)
+		}
+		for j := range DCTBlock {
+			c[k*DCTBlock+j] = s * math.Cos(math.Pi*float64((2*j+1)*k)/(2*DCTBlock))
+		}
+	}
+	return c
+}()
+
+// 2-D DCT of an 8*8 block, as C*B*Cᵀ, or its inverse as Cᵀ*B*C
+func dctBlock(b View[float64], dst []float64, inverse bool) {
+	const n = DCTBlock
+	c := &dctBlockMatrix
+	var t [n * n]float64
+	for k := range n {
+		for x := range n {
+			s := 0.0
+			for j := range n {
+				if inverse {
+					s += c[j*n+k] * b.at(x, j)
+				} else {
+					s += c[k*n+j] * b.at(x, j)
+				}
+			}
+			t[k*n+x] = s
+		}
+	}
+	for y := range n {
+		for k := range n {
+			s := 0.0
+			for j := range n {
+				if inverse {
+					s += t[y*n+j] * c[j*n+k]
+				} else {
+					s += t[y*n+j] * c[k*n+j]
+				}
+			}
+			dst[y*n+k] = s
+		}
+	}
+}
+
+// Transform each 8*8 block of a matrix by the 2-D DCT, in place of the block.
+// The matrix is padded to multiples of 8 by replicating its borders first.
+func BlockDCT[T types.Real](m Matrix[T]) (General[float64], error) {
+	m1 := float64Matrix(m)
+	if m1.Empty() {
+		return General[float64]{}, ErrEmptyMatrix
+	}
+	m1 = m1.Pad(0, 0, -m1.x&(DCTBlock-1), -m1.y&(DCTBlock-1), Border[float64]{Mode: PadReplicate})
+	return blockTransform(m1.View(), false), nil
+}
+
+// Inverse of [BlockDCT], cropped to dimensions (x,y)
+func IBlockDCT(m Matrix[float64], x, y int) (General[float64], error) {
+	d := m.Dims()
+	if d[0]%DCTBlock != 0 || d[1]%DCTBlock != 0 || x > d[0] || y > d[1] || x <= 0 || y <= 0 {
+		return General[float64]{}, &DimensionError{
+			Op:   "IBlockDCT",
+			Dims: []Index2{d, {x, y}},
+			Why:  ErrDimensions,
+		}
+	}
+	r := blockTransform(ViewOf(m), true)
+	return r.Pad(0, 0, x-r.x, y-r.y, Border[float64]{}), nil
+}
+
+func blockTransform(m View[float64], inverse bool) General[float64] {
+	r := NewGeneral[float64](m.x, m.y)
+	var b [DCTBlock * DCTBlock]float64
+	for p, v := range m.RangeSubMatrix(DCTBlock, DCTBlock, DCTBlock, DCTBlock) {
+		dctBlock(v, b[:], inverse)
+		for j := range DCTBlock {
+			copy(r.val[(p[1]+j)*r.x+p[0]:], b[j*DCTBlock:(j+1)*DCTBlock])
+		}
+	}
+	return r
+}
+
+// Quantize the coefficients of a block DCT by q, rounding them to the nearest multiples
+func Quantize(m Matrix[float64], q QuantTable) General[int] {
+	v := ViewOf(m)
+	r := NewGeneral[int](v.x, v.y)
+	for p, t := range v.Range(1, 1) {
+		r.val[p[1]*r.x+p[0]] = int(math.Round(t / float64(q[p[1]%DCTBlock*DCTBlock+p[0]%DCTBlock])))
+	}
+	return r
+}
+
+// Restore the coefficients of a block DCT quantized by q
+func Dequantize(m Matrix[int], q QuantTable) General[float64] {
+	v := ViewOf(m)
+	r := NewGeneral[float64](v.x, v.y)
+	for p, t := range v.Range(1, 1) {
+		r.val[p[1]*r.x+p[0]] = float64(t) * float64(q[p[1]%DCTBlock*DCTBlock+p[0]%DCTBlock])
+	}
+	return r
+}
+
+// Compress and decompress an 8-bit channel by the block DCT of JPEG with the table q,
+// level-shifted by 128, to study the artifacts without the entropy coding
+func BlockDCTRoundTrip(m Matrix[uint8], q QuantTable) (General[uint8], error) {
+	d := m.Dims()
+	c, err := BlockDCT(MapMatrix(m, func(t uint8) float64 { return float64(t) - 128 }))
+	if err != nil {
+		return General[uint8]{}, err
+	}
+	r, err := IBlockDCT(Dequantize(Quantize(c, q), q), d[0], d[1])
+	if err != nil {
+		return General[uint8]{}, err
+	}
+	return MapMatrix(r, func(t float64) uint8 { return uint8(min(max(math.Round(t+128), 0), 255)) }), nil
+}
+
+// Index of the elements of an x*y matrix in zig-zag order,
+// along the antidiagonals from (0,0), as the coefficients of JPEG
+func ZigZagOrder(x, y int) []Index2 {
+	if x <= 0 || y <= 0 {
+		return nil
+	}
+	r := make([]Index2, 0, x*y)
+	for s := range x + y - 1 {
+		lo, hi := max(0, s-x+1), min(s, y-1) // range of the row index
+		if s%2 == 0 {
+			for j := hi; j >= lo; j-- {
+				r = append(r, Index2{s - j, j})
+			}
+		} else {
+			for j := lo; j <= hi; j++ {
+				r = append(r, Index2{s - j, j})
+			}
+		}
+	}
+	return r
+}
+
+// Elements of a matrix in zig-zag order
+func ZigZag[T types.Number](m Matrix[T]) []T {
+	v := ViewOf(m)
+	r := make([]T, 0, v.x*v.y)
+	for _, p := range ZigZagOrder(v.x, v.y) {
+		r = append(r, v.at(p[0], p[1]))
+	}
+	return r
+}
+
+// x*y matrix of the elements in zig-zag order, the inverse of [ZigZag]
+func UnZigZag[T types.Number](s []T, x, y int) (General[T], error) {
+	if len(s) != x*y {
+		return General[T]{}, &DimensionError{
+			Op:   "UnZigZag",
+			Dims: []Index2{{len(s), 1}, {x, y}},
+			Why:  ErrDimensions,
+		}
+	}
+	r := NewGeneral[T](x, y)
+	for i, p := range ZigZagOrder(x, y) {
+		r.val[p[1]*x+p[0]] = s[i]
+	}
+	return r, nil
+}
@@ -0,0 +1,184 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/cmplx"
+)
+
+// Point spread functions (PSF) model the blur of an image as the convolution
+// g(x,y) = Σ h(i,j) f(x-i,y-j), of h centered at (x/2,y/2) of the PSF,
+// normalized to sum 1. They are of odd dimensions, so that they are centered exactly.
+
+// Normalize a PSF to sum 1
+func normalizePSF(m General[float64]) General[float64] {
+	s := 0.0
+	for _, t := range m.val {
+		s += t
+	}
+	if s != 0 {
+		for i := range m.val {
+			m.val[i] /= s
+		}
+	}
+	return m
+}
+
+// Gaussian PSF of standard deviation sigma in pixels, truncated at 3 sigma
+func GaussianPSF(sigma float64) General[float64] {
+	return GaussianKernel(sigma)
+}
+
+// PSF of defocus, a uniform disk of the given radius in pixels,
+// of which the pixels on the edge are weighted by their area inside
+func DiskPSF(radius float64) General[float64] {
+	return DiskKernel(radius)
+}
+
+// Radius in pixels of the defocus disk of a point of light focused at distance v
+// behind the lens, while the sensor lies at distance sensor, of the aperture radius
+// and pixel pitch, as cameraRadius, lensDistance and pixelWidth
+func DefocusRadius(aperture, sensor, v, pixel float64) float64 {
+	return aperture * math.Abs(v-sensor) / v / pixel
+}
+
+// PSF of linear motion of the given length in pixels, along angle in radians
+// counterclockwise from the x axis, with y increasing downward.
+// The line is drawn by bilinear splatting of dense samples.
+func MotionPSF(length, angle float64) General[float64] {
+	s, c := math.Sincos(angle)
+	hx, hy := math.Abs(c)*length/2, math.Abs(s)*length/2
+	rx, ry := int(math.Ceil(hx)), int(math.Ceil(hy))
+	m := NewGeneral[float64](2*rx+1, 2*ry+1)
+	n := max(int(math.Ceil(length*4)), 1)
+	for i := range n + 1 {
+		t := length * (float64(i)/float64(n) - 0.5)
+		x, y := float64(rx)+t*c, float64(ry)-t*s
+		x0, y0 := math.Floor(x), math.Floor(y)
+		fx, fy := x-x0, y-y0
+		for _, p := range [...]struct {
+			x, y int
+			w    float64
+		}{
+			{int(x0), int(y0), (1 - fx) * (1 - fy)},
+			{int(x0) + 1, int(y0), fx * (1 - fy)},
+			{int(x0), int(y0) + 1, (1 - fx) * fy},
+			{int(x0) + 1, int(y0) + 1, fx * fy},
+		} {
+			if p.w > 0 && p.x >= 0 && p.x < m.x && p.y >= 0 && p.y < m.y {
+				m.val[p.y*m.x+p.x] += p.w
+			}
+		}
+	}
+	return normalizePSF(m)
+}
+
+// Rotate a PSF by 180°, turning convolution into correlation, as by Conv
+func flipPSF(m General[float64]) General[float64] {
+	r := NewGeneral[float64](m.x, m.y)
+	for i, t := range m.val {
+		r.val[len(r.val)-1-i] = t
+	}
+	return r
+}
+
+// Blur m by the PSF, with the borders extended by reflection
+func blurPSF(m, psf General[float64]) (General[float64], error) {
+	return ConvFast(m, flipPSF(psf), Border[float64]{Mode: PadReflect}, ConvSame, ConvAuto)
+}
+
+// Wiener deconvolution of m blurred by psf, with snr the ratio of the power of
+// the signal to that of the noise, as SignalNoiseRatio.
+//
+// The spectrum of the estimate is G*conj(H)/(|H|²+1/snr), G and H being those of m and psf.
+// m is extended periodically with smooth transitions beforehand, against ringing at the borders.
+func WienerDeconv[T types.Real](m Matrix[T], psf Matrix[float64], snr float64) (General[float64], error) {
+	m1, h := float64Matrix(m), normalizePSF(ViewOf(psf).Clone())
+	if m1.Empty() || h.Empty() {
+		return General[float64]{}, ErrEmptyMatrix
+	} else if !(snr > 0) {
+		return General[float64]{}, ErrOutOfBounds
+	}
+	px, py := 2*h.x, 2*h.y
+	nx, ny := fftSize(m1.x+2*px), fftSize(m1.y+2*py)
+	p, err := NewFFTPlan2(nx, ny)
+	if err != nil {
+		return General[float64]{}, err
+	}
+	g, err := p.Real(taperPad(m1, nx, ny), NormBackward)
+	if err != nil {
+		return General[float64]{}, err
+	}
+	// PSF of its center moved to (0,0), wrapping around
+	hp := NewGeneral[float64](nx, ny)
+	for i, t := range h.val {
+		x, y := (i%h.x-h.x/2+nx)%nx, (i/h.x-h.y/2+ny)%ny
+		hp.val[y*nx+x] += t
+	}
+	hf, _ := p.Real(hp, NormBackward)
+	k := 1 / snr
+	for i, c := range hf.val {
+		a := real(c)*real(c) + imag(c)*imag(c)
+		g.val[i] *= cmplx.Conj(c) / complex(a+k, 0)
+	}
+	f, err := p.RealInverse(g, NormBackward)
+	if err != nil {
+		return General[float64]{}, err
+	}
+	return f.Pad(0, 0, m1.x-nx, m1.y-ny, Border[float64]{}), nil
+}
+
+// Pad m to dimensions (x,y), blending its reflections across the right and left borders,
+// and the bottom and top ones, so that it is continuous when repeated periodically
+func taperPad(m General[float64], x, y int) General[float64] {
+	b := Border[float64]{Mode: PadReflect}
+	r := NewGeneral[float64](x, y)
+	blend := func(dst []float64, n, l, stride int, at func(i int) float64) {
+		for i := range n {
+			dst[i*stride] = at(i)
+		}
+		for t := range l {
+			w := (float64(t) + 0.5) / float64(l)
+			dst[(n+t)*stride] = (1-w)*at(b.source(n+t, n)) + w*at(b.source(t-l, n))
+		}
+	}
+	for j := range m.y {
+		blend(r.val[j*x:], m.x, x-m.x, 1, func(i int) float64 { return m.val[j*m.x+i] })
+	}
+	for i := range x {
+		blend(r.val[i:], m.y, y-m.y, x, func(j int) float64 { return r.val[j*x+i] })
+	}
+	return r
+}
+
+// Richardson–Lucy deconvolution of m blurred by psf, by the given number of iterations.
+//
+// Each iteration multiplies the estimate by the correlation of psf with the ratio of m
+// to the estimate blurred, which converges to the maximum likelihood estimate
+// under Poisson noise. m must be non-negative; the estimate stays so.
+func RichardsonLucy[T types.Real](m Matrix[T], psf Matrix[float64], iterations int) (General[float64], error) {
+	m1, h := float64Matrix(m), normalizePSF(ViewOf(psf).Clone())
+	if m1.Empty() || h.Empty() {
+		return General[float64]{}, ErrEmptyMatrix
+	}
+	const eps = 1e-12 // against division by zero where the estimate vanishes
+	f := m1.Clone()
+	ratio := NewGeneral[float64](m1.x, m1.y)
+	for range iterations {
+		b, err := blurPSF(f, h)
+		if err != nil {
+			return General[float64]{}, err
+		}
+		for i, t := range b.val {
+			ratio.val[i] = m1.val[i] / max(t, eps)
+		}
+		c, err := blurPSF(ratio, flipPSF(h))
+		if err != nil {
+			return General[float64]{}, err
+		}
+		for i, t := range c.val {
+			f.val[i] *= t
+		}
+	}
+	return f, nil
+}
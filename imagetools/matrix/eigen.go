@@ -0,0 +1,457 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// Maximum sweeps or iterations of the eigenvalue solvers
+const eigenMaxIter = 64
+
+func float64Matrix[T types.Real](m Matrix[T]) General[float64] {
+	return MapMatrix(m, func(t T) float64 { return float64(t) })
+}
+
+// Eigenvalues and eigenvectors of a real symmetric matrix, by cyclic Jacobi rotations.
+//
+// The eigenvalues are returned as an n-by-1 column in descending order,
+// and the i-th column of vectors is the unit eigenvector of the i-th eigenvalue.
+func EigenSym[T types.Real](mat Matrix[T]) (values, vectors General[float64], err error) {
+	a := float64Matrix(mat)
+	if a.Empty() {
+		return values, vectors, ErrEmptyMatrix
+	} else if a.x != a.y {
+		return values, vectors, &DimensionError{
+			Op:   "EigenSym",
+			Dims: []Index2{a.Dims()},
+			Why:  ErrNotSquare,
+		}
+	}
+	n := a.x
+	norm := 0.0
+	for i := range n {
+		for j := range i {
+			u, v := a.val[i*n+j], a.val[j*n+i]
+			if types.Abs(u-v) > 1e-9*max(types.Abs(u), types.Abs(v), 1) {
+				return values, vectors, ErrAsymmetric
+			}
+		}
+	}
+	for _, v := range a.val {
+		norm += v * v
+	}
+	q := IdentityGeneral[float64](n)
+	converged := false
+	// Tested after each sweep as well as before the first
+	for sweep := 0; ; sweep++ {
+		off := 0.0
+		for i := range n {
+			for j := range i {
+				off += 2 * a.val[i*n+j] * a.val[i*n+j]
+			}
+		}
+		if off <= 1e-30*norm {
+			converged = true
+			break
+		}
+		if sweep == eigenMaxIter {
+			break
+		}
+		for p := range n {
+			for r := p + 1; r < n; r++ {
+				if a.val[p*n+r] != 0 {
+					jacobiRotate(a, q, p, r)
+				}
+			}
+		}
+	}
+	if !converged {
+		return values, vectors, ErrNoConverge
+	}
+	// Sort eigenpairs in descending order of eigenvalues
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(i, j int) int {
+		return types.Compare(a.val[j*n+j], a.val[i*n+i])
+	})
+	values, vectors = NewGeneral[float64](1, n), NewGeneral[float64](n, n)
+	for i, k := range idx {
+		values.val[i] = a.val[k*n+k]
+		for j := range n {
+			vectors.val[j*n+i] = q.val[j*n+k]
+		}
+	}
+	return values, vectors, nil
+}
+
+// Apply the Jacobi rotation annihilating a[p,r] to a, and accumulate it into q
+func jacobiRotate(a, q General[float64], p, r int) {
+	n := a.x
+	tau := (a.val[r*n+r] - a.val[p*n+p]) / (2 * a.val[p*n+r])
+	t := 1 / (types.Abs(tau) + math.Hypot(1, tau))
+	if tau < 0 {
+		t = -t
+	}
+	c := 1 / math.Hypot(1, t)
+	s := t * c
+	for k := range n {
+		u, v := a.val[k*n+p], a.val[k*n+r]
+		a.val[k*n+p], a.val[k*n+r] = c*u-s*v, s*u+c*v
+	}
+	for k := range n {
+		u, v := a.val[p*n+k], a.val[r*n+k]
+		a.val[p*n+k], a.val[r*n+k] = c*u-s*v, s*u+c*v
+	}
+	for k := range n {
+		u, v := q.val[k*n+p], q.val[k*n+r]
+		q.val[k*n+p], q.val[k*n+r] = c*u-s*v, s*u+c*v
+	}
+	a.val[p*n+r], a.val[r*n+p] = 0, 0
+}
+
+// Identity matrix of order n
+func IdentityGeneral[T types.Number](n int) General[T] {
+	m := NewGeneral[T](n, n)
+	for i := range n {
+		m.val[i*n+i] = 1
+	}
+	return m
+}
+
+// Balance a square matrix in place, so that its rows and columns have comparable norms,
+// which improves the accuracy of the eigenvalues.
+func balance(a General[float64]) {
+	const radix = 2
+	n := a.x
+	for done := false; !done; {
+		done = true
+		for i := range n {
+			r, c := 0.0, 0.0
+			for j := range n {
+				if j != i {
+					c += types.Abs(a.val[j*n+i])
+					r += types.Abs(a.val[i*n+j])
+				}
+			}
+			if c == 0 || r == 0 {
+				continue
+			}
+			g, f, s := r/radix, 1.0, c+r
+			for c < g {
+				f *= radix
+				c *= radix * radix
+			}
+			for g = r * radix; c > g; {
+				f /= radix
+				c /= radix * radix
+			}
+			if (c+r)/f < 0.95*s {
+				done = false
+				for j := range n {
+					a.val[i*n+j] /= f
+					a.val[j*n+i] *= f
+				}
+			}
+		}
+	}
+}
+
+// Reduce a square matrix to an upper Hessenberg matrix similar to it,
+// by stabilized elementary similarity transformations.
+func Hessenberg[T types.Real](mat Matrix[T]) (General[float64], error) {
+	a := float64Matrix(mat)
+	if a.x != a.y {
+		return General[float64]{}, &DimensionError{
+			Op:   "Hessenberg",
+			Dims: []Index2{a.Dims()},
+			Why:  ErrNotSquare,
+		}
+	}
+	hessenberg(a)
+	return a, nil
+}
+
+func hessenberg(a General[float64]) {
+	n := a.x
+	for k := 1; k < n-1; k++ {
+		x, i := 0.0, k
+		for j := k; j < n; j++ {
+			if types.Abs(a.val[j*n+k-1]) > types.Abs(x) {
+				x, i = a.val[j*n+k-1], j
+			}
+		}
+		if i != k {
+			a.Elem1(false, i, k)
+			a.Elem1(true, i, k)
+		}
+		if x == 0 {
+			continue
+		}
+		for i := k + 1; i < n; i++ {
+			y := a.val[i*n+k-1]
+			if y == 0 {
+				continue
+			}
+			y /= x
+			for j := k - 1; j < n; j++ {
+				a.val[i*n+j] -= y * a.val[k*n+j]
+			}
+			for j := range n {
+				a.val[j*n+k] += y * a.val[j*n+i]
+			}
+			a.val[i*n+k-1] = 0
+		}
+	}
+}
+
+// Eigenvalues of a real square matrix, by balancing, Hessenberg reduction
+// and the shifted QR algorithm.
+//
+// The eigenvalues are returned as an n-by-1 column, sorted by descending real part,
+// with complex conjugate pairs adjacent and the positive imaginary part first.
+func Eigen[T types.Real](mat Matrix[T]) (General[complex128], error) {
+	a := float64Matrix(mat)
+	if a.Empty() {
+		return General[complex128]{}, ErrEmptyMatrix
+	} else if a.x != a.y {
+		return General[complex128]{}, &DimensionError{
+			Op:   "Eigen",
+			Dims: []Index2{a.Dims()},
+			Why:  ErrNotSquare,
+		}
+	}
+	balance(a)
+	hessenberg(a)
+	w, err := hqr(a)
+	if err != nil {
+		return General[complex128]{}, err
+	}
+	slices.SortStableFunc(w, func(u, v complex128) int {
+		if c := types.Compare(real(v), real(u)); c != 0 {
+			return c
+		}
+		return types.Compare(imag(v), imag(u))
+	})
+	return NewGeneral(1, len(w), w...), nil
+}
+
+// Eigenvalues of an upper Hessenberg matrix, destroying it.
+func hqr(a General[float64]) ([]complex128, error) {
+	n := a.x
+	w := make([]complex128, n)
+	at := func(i, j int) *float64 { return &a.val[i*n+j] }
+	sign := func(a, b float64) float64 {
+		if b >= 0 {
+			return types.Abs(a)
+		}
+		return -types.Abs(a)
+	}
+	anorm := 0.0
+	for i := range n {
+		for j := max(i-1, 0); j < n; j++ {
+			anorm += types.Abs(*at(i, j))
+		}
+	}
+	var p, q, r, s, t, x, y, z float64
+	for nn := n - 1; nn >= 0; {
+		its, l := 0, 0
+		for {
+			for l = nn; l > 0; l-- {
+				s = types.Abs(*at(l-1, l-1)) + types.Abs(*at(l, l))
+				if s == 0 {
+					s = anorm
+				}
+				if types.Abs(*at(l, l-1)) <= 0x1p-52*s {
+					*at(l, l-1) = 0
+					break
+				}
+			}
+			x = *at(nn, nn)
+			if l == nn { // one root found
+				w[nn] = complex(x+t, 0)
+				nn--
+				break
+			}
+			y = *at(nn-1, nn-1)
+			ww := *at(nn, nn-1) * *at(nn-1, nn)
+			if l == nn-1 { // two roots found
+				p = (y - x) / 2
+				q = p*p + ww
+				z = math.Sqrt(types.Abs(q))
+				x += t
+				if q >= 0 {
+					z = p + sign(z, p)
+					w[nn-1], w[nn] = complex(x+z, 0), complex(x+z, 0)
+					if z != 0 {
+						w[nn] = complex(x-ww/z, 0)
+					}
+				} else {
+					w[nn-1], w[nn] = complex(x+p, z), complex(x+p, -z)
+				}
+				nn -= 2
+				break
+			}
+			if its == eigenMaxIter {
+				return nil, ErrNoConverge
+			}
+			if its == 10 || its == 20 { // exceptional shift
+				t += x
+				for i := range nn + 1 {
+					*at(i, i) -= x
+				}
+				s = types.Abs(*at(nn, nn-1)) + types.Abs(*at(nn-1, nn-2))
+				x, y = 0.75*s, 0.75*s
+				ww = -0.4375 * s * s
+			}
+			its++
+			m := nn - 2
+			for ; m >= l; m-- {
+				z = *at(m, m)
+				r, s = x-z, y-z
+				p = (r*s-ww) / *at(m+1, m) + *at(m, m+1)
+				q = *at(m+1, m+1) - z - r - s
+				r = *at(m+2, m+1)
+				s = types.Abs(p) + types.Abs(q) + types.Abs(r)
+				p, q, r = p/s, q/s, r/s
+				if m == l {
+					break
+				}
+				u := types.Abs(*at(m, m-1)) * (types.Abs(q) + types.Abs(r))
+				v := types.Abs(p) * (types.Abs(*at(m-1, m-1)) + types.Abs(z) + types.Abs(*at(m+1, m+1)))
+				if u <= 0x1p-52*v {
+					break
+				}
+			}
+			for i := m; i < nn-1; i++ {
+				*at(i+2, i) = 0
+				if i != m {
+					*at(i+2, i-1) = 0
+				}
+			}
+			for k := m; k < nn; k++ { // double QR step
+				if k != m {
+					p, q, r = *at(k, k-1), *at(k+1, k-1), 0
+					if k+1 != nn {
+						r = *at(k+2, k-1)
+					}
+					if x = types.Abs(p) + types.Abs(q) + types.Abs(r); x != 0 {
+						p, q, r = p/x, q/x, r/x
+					}
+				}
+				if s = sign(math.Sqrt(p*p+q*q+r*r), p); s == 0 {
+					continue
+				}
+				if k == m {
+					if l != m {
+						*at(k, k-1) = -*at(k, k-1)
+					}
+				} else {
+					*at(k, k-1) = -s * x
+				}
+				p += s
+				x, y, z = p/s, q/s, r/s
+				q, r = q/p, r/p
+				for j := k; j <= nn; j++ {
+					p = *at(k, j) + q**at(k+1, j)
+					if k+1 != nn {
+						p += r * *at(k+2, j)
+						*at(k+2, j) -= p * z
+					}
+					*at(k+1, j) -= p * y
+					*at(k, j) -= p * x
+				}
+				for i := l; i <= min(nn, k+3); i++ {
+					p = x**at(i, k) + y**at(i, k+1)
+					if k+1 != nn {
+						p += z * *at(i, k+2)
+						*at(i, k+2) -= p * r
+					}
+					*at(i, k+1) -= p * q
+					*at(i, k) -= p
+				}
+			}
+		}
+	}
+	return w, nil
+}
+
+// Dominant eigenvalue and its unit eigenvector of a square matrix, by power iteration.
+//
+// The iteration stops when the eigenvalue estimate changes by no more than tol (relatively),
+// or fails with [ErrNoConverge] after maxIter iterations.
+// An iterate falling into the null space restarts it once from a random vector.
+// It only involves matrix-vector products, which suits large matrices.
+func PowerIteration[T types.Real](mat Matrix[T], maxIter int, tol float64) (float64, []float64, error) {
+	a := float64Matrix(mat)
+	if a.Empty() {
+		return 0, nil, ErrEmptyMatrix
+	} else if a.x != a.y {
+		return 0, nil, &DimensionError{
+			Op:   "PowerIteration",
+			Dims: []Index2{a.Dims()},
+			Why:  ErrNotSquare,
+		}
+	}
+	n := a.x
+	v, u := make([]float64, n), make([]float64, n)
+	for i := range v {
+		v[i] = 1 + float64(i)/float64(n) // unlikely to be orthogonal to the eigenvector
+	}
+	normalize(v)
+	lambda := 0.0
+	restarted := false
+	for range maxIter {
+		for i := range n {
+			u[i] = dot(a.val[i*n:(i+1)*n], v)
+		}
+		l := dot(u, v) // Rayleigh quotient
+		if normalize(u) == 0 {
+			if restarted {
+				// Some power of the matrix vanishes on a random vector, so it is nilpotent,
+				// of all its eigenvalues 0, and v is an eigenvector
+				return 0, v, nil
+			}
+			restarted = true
+			rng := rand.New(rand.NewSource(1))
+			for i := range v {
+				v[i] = rng.NormFloat64()
+			}
+			normalize(v)
+			lambda = 0
+			continue
+		}
+		if l < 0 { // keep the sign of the eigenvector stable
+			for i := range u {
+				u[i] = -u[i]
+			}
+		}
+		u, v = v, u
+		if types.Abs(l-lambda) <= tol*types.Abs(l) {
+			return l, v, nil
+		}
+		lambda = l
+	}
+	return lambda, v, ErrNoConverge
+}
+
+func dot(x, y []float64) (s float64) {
+	for i, v := range x {
+		s += v * y[i]
+	}
+	return s
+}
+
+// Normalize vector x to unit length, returning its original length
+func normalize(x []float64) float64 {
+	l := math.Sqrt(dot(x, x))
+	if l != 0 {
+		for i := range x {
+			x[i] /= l
+		}
+	}
+	return l
+}
@@ -1,38 +1,71 @@
-package imagetools
-
-type BasicError string
-
-func (s BasicError) Error() string {
-	return string(s)
-}
-
-const (
-	ErrDimensions  BasicError = "inconsistent dimensions"
-	ErrEmptyMatrix BasicError = "empty matrix"
-	ErrNotSquare   BasicError = "not square matrix"
-	ErrOutOfBounds BasicError = "out of bounds"
-	ErrDivideBy0   BasicError = "division by zero"
-	ErrLargeKernel BasicError = "the kernel is too large"
-	ErrInvalidStep BasicError = "the step is not positive interger"
-)
-
-type DimensionError struct {
-	Dims []Index2
-	Op   string
-	Why  error
-}
-
-// Matrix dimensional error
-func (e DimensionError) Error() string {
-	s := "matrix dimension error: " + e.Op + "("
-	for i, v := range e.Dims {
-		if i > 0 {
-			s += ","
-		}
-		s += v.String()
-	}
-	return s + ")" + e.Why.Error()
-}
-func (e DimensionError) Unwrap() error {
-	return e.Why
-}
+package imagetools
+
+import types "imagetools/types"
+
+type BasicError string
+
+func (s BasicError) Error() string {
+	return string(s)
+}
+
+const (
+	ErrDimensions  BasicError = "inconsistent dimensions"
+	ErrEmptyMatrix BasicError = "empty matrix"
+	ErrNotSquare   BasicError = "not square matrix"
+	ErrOutOfBounds BasicError = "out of bounds"
+	ErrDivideBy0   BasicError = "division by zero"
+	ErrLargeKernel BasicError = "the kernel is too large"
+	ErrInvalidStep BasicError = "the step is not positive interger"
+	ErrAsymmetric  BasicError = "not symmetric matrix"
+	ErrNoConverge  BasicError = "iteration does not converge"
+	ErrInseparable BasicError = "the kernel is not separable"
+)
+
+type DimensionError struct {
+	Dims []Index2
+	Op   string
+	Why  error
+}
+
+// Matrix dimensional error
+func (e DimensionError) Error() string {
+	s := "matrix dimension error: " + e.Op + "("
+	for i, v := range e.Dims {
+		if i > 0 {
+			s += ","
+		}
+		s += v.String()
+	}
+	return s + ")" + e.Why.Error()
+}
+func (e DimensionError) Unwrap() error {
+	return e.Why
+}
+
+type ShapeError struct {
+	Shapes [][]int
+	Op     string
+	Why    error
+}
+
+// Tensor shape error
+func (e ShapeError) Error() string {
+	s := "tensor shape error: " + e.Op + "("
+	for i, v := range e.Shapes {
+		if i > 0 {
+			s += ","
+		}
+		s += "["
+		for j, n := range v {
+			if j > 0 {
+				s += " "
+			}
+			s += types.FormatNumber(n, 10, 0)
+		}
+		s += "]"
+	}
+	return s + ")" + e.Why.Error()
+}
+func (e ShapeError) Unwrap() error {
+	return e.Why
+}
@@ -0,0 +1,147 @@
+package matrix
+
+import types "imagetools/types"
+
+// Lazily evaluated elementwise expression of matrices, with broadcasting.
+//
+// Operations only record the computation, which Eval fuses into a single pass
+// over the result, without any intermediate matrix:
+//
+//	m, err := Expr(a).Mul(b).Add(c).Eval()
+//
+// The first error, such as a [DimensionError] of incompatible dimensions,
+// is kept and returned by Eval. Expressions implement [Matrix], so they can be
+// nested as operands of other expressions, still without materialization.
+type Expression[T types.Number] struct {
+	d   Index2
+	f   func(x, y int) T
+	err error
+}
+
+// Start an expression from a matrix, which must not change until evaluation
+func Expr[T types.Number](m Matrix[T]) Expression[T] {
+	if e, ok := m.(Expression[T]); ok {
+		return e
+	}
+	v := ViewOf(m)
+	return Expression[T]{
+		d: v.Dims(),
+		f: func(x, y int) T { return v.val[v.off+x*v.sx+y*v.sy] },
+	}
+}
+
+// Get the dimension lengths of the result
+func (e Expression[T]) Dims() Index2 {
+	return e.d
+}
+
+// Evaluate the element at (x,y)
+func (e Expression[T]) At(x, y int) (T, error) {
+	if e.err != nil {
+		return 0, e.err
+	} else if x < 0 || x >= e.d[0] || y < 0 || y >= e.d[1] {
+		return 0, ErrOutOfBounds
+	}
+	return e.f(x, y), nil
+}
+
+// Get the first error of the expression
+func (e Expression[T]) Err() error {
+	return e.err
+}
+
+// Stretch f, of dimensions d0, to dimensions d by broadcasting
+func stretch[T types.Number](f func(x, y int) T, d0, d Index2) func(x, y int) T {
+	switch bx, by := d0[0] == 1 && d[0] != 1, d0[1] == 1 && d[1] != 1; {
+	case bx && by:
+		return func(x, y int) T { return f(0, 0) }
+	case bx:
+		return func(x, y int) T { return f(0, y) }
+	case by:
+		return func(x, y int) T { return f(x, 0) }
+	}
+	return f
+}
+
+func (e Expression[T]) zip(op string, m Matrix[T], g func(s, t T) T) Expression[T] {
+	if e.err != nil {
+		return e
+	}
+	e1 := Expr(m)
+	if e1.err != nil {
+		return e1
+	}
+	d, err := broadcastDims(op, e.d, e1.d)
+	if err != nil {
+		return Expression[T]{d: e.d, err: err}
+	}
+	f, f1 := stretch(e.f, e.d, d), stretch(e1.f, e1.d, d)
+	return Expression[T]{
+		d: d,
+		f: func(x, y int) T { return g(f(x, y), f1(x, y)) },
+	}
+}
+
+// Add m elementwise
+func (e Expression[T]) Add(m Matrix[T]) Expression[T] {
+	return e.zip("Expression.Add", m, func(s, t T) T { return s + t })
+}
+
+// Subtract m elementwise
+func (e Expression[T]) Sub(m Matrix[T]) Expression[T] {
+	return e.zip("Expression.Sub", m, func(s, t T) T { return s - t })
+}
+
+// Multiply by m elementwise
+func (e Expression[T]) Mul(m Matrix[T]) Expression[T] {
+	return e.zip("Expression.Mul", m, func(s, t T) T { return s * t })
+}
+
+// Divide by m elementwise.
+// Integer divisors are checked up front as by [DivElem], evaluating m if it is
+// an expression, and a zero makes the expression fail with [ErrDivideBy0].
+func (e Expression[T]) Div(m Matrix[T]) Expression[T] {
+	r := e.zip("Expression.Div", m, func(s, t T) T { return s / t })
+	if r.err == nil && isInteger[T]() {
+		for _, t := range elements(m) {
+			if t == 0 {
+				return Expression[T]{d: r.d, err: ErrDivideBy0}
+			}
+		}
+	}
+	return r
+}
+
+// Multiply by number t
+func (e Expression[T]) Scale(t T) Expression[T] {
+	return e.Map(func(s T) T { return s * t })
+}
+
+// Add number t
+func (e Expression[T]) Offset(t T) Expression[T] {
+	return e.Map(func(s T) T { return s + t })
+}
+
+// Apply f to each element
+func (e Expression[T]) Map(g func(T) T) Expression[T] {
+	if e.err != nil {
+		return e
+	}
+	f := e.f
+	e.f = func(x, y int) T { return g(f(x, y)) }
+	return e
+}
+
+// Evaluate the expression into a new matrix
+func (e Expression[T]) Eval() (General[T], error) {
+	if e.err != nil {
+		return General[T]{}, e.err
+	}
+	m := NewGeneral[T](e.d[0], e.d[1])
+	for y := range m.y {
+		for x := range m.x {
+			m.val[y*m.x+x] = e.f(x, y)
+		}
+	}
+	return m, nil
+}
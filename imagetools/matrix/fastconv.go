@@ -0,0 +1,144 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+)
+
+// Strategy of [ConvFast]
+type ConvMethod int
+
+const (
+	ConvAuto      ConvMethod = iota // separable if possible, FFT for large kernels, direct otherwise
+	ConvDirect                      // nested loops, O(W*H*kW*kH)
+	ConvSeparable                   // a row pass and a column pass, O(W*H*(kW+kH))
+	ConvFFT                         // pointwise product of spectra, O(W*H*log(W*H))
+)
+
+// Minimum kernel area above which ConvAuto uses FFT convolution
+const fftConvArea = 121
+
+// Factorize a kernel as the outer product of a column and a row,
+// by its singular value decomposition, if the kernel is rank 1 up to rounding errors.
+func SeparateKernel[T types.Real](k Matrix[T]) (col, row []float64, ok bool) {
+	kernel := float64Matrix(k)
+	if kernel.Empty() {
+		return nil, nil, false
+	} else if kernel.y == 1 {
+		return []float64{1}, kernel.val, true
+	} else if kernel.x == 1 {
+		return kernel.val, []float64{1}, true
+	}
+	ktk, _ := MulMatTrans(kernel, kernel, true, false)
+	s, v, err := EigenSym(*ktk) // squared singular values and right singular vectors
+	if err != nil || s.val[0] <= 0 {
+		return nil, nil, false
+	}
+	row = make([]float64, kernel.x)
+	for i := range row {
+		row[i] = v.val[i*v.x]
+	}
+	col = make([]float64, kernel.y)
+	for j := range col {
+		col[j] = dot(kernel.val[j*kernel.x:(j+1)*kernel.x], row)
+	}
+	// Rank 1 if the factors reproduce the kernel up to rounding errors
+	tol := 1e-12 * math.Sqrt(s.val[0])
+	for i, t := range kernel.val {
+		if math.Abs(t-col[i/kernel.x]*row[i%kernel.x]) > tol {
+			return nil, nil, false
+		}
+	}
+	return col, row, true
+}
+
+// Convolution of real matrices in float64 with step 1, by the selected strategy,
+// of output size selected by size, with the elements beyond the borders extrapolated by border.
+// All strategies agree up to rounding errors, except that ConvSeparable fails
+// with [ErrInseparable] for kernels of rank more than 1.
+func ConvFast[T types.Real](m, k Matrix[T], border Border[float64], size ConvSize, method ConvMethod) (General[float64], error) {
+	m1, kernel := float64Matrix(m), float64Matrix(k)
+	if m1.Empty() || kernel.Empty() {
+		return General[float64]{}, ErrEmptyMatrix
+	}
+	if method == ConvAuto || method == ConvSeparable {
+		if col, row, ok := SeparateKernel(kernel); ok {
+			return ConvSeparablePass(m1, col, row, border, size)
+		} else if method == ConvSeparable {
+			return General[float64]{}, ErrInseparable
+		} else if kernel.x*kernel.y >= fftConvArea {
+			method = ConvFFT
+		}
+	}
+	p := m1.padConv(kernel.Dims(), border, size)
+	if p.x < kernel.x || p.y < kernel.y {
+		return General[float64]{}, &DimensionError{
+			Op:   "ConvFast",
+			Dims: []Index2{p.Dims(), kernel.Dims()},
+			Why:  ErrLargeKernel,
+		}
+	}
+	if method == ConvFFT {
+		return convFFT(p, kernel), nil
+	}
+	r, err := p.Conv(kernel, 1, 1)
+	if r == nil {
+		return General[float64]{}, err
+	}
+	return *r, nil
+}
+
+// Convolution with the separable kernel col*row, as a row pass and a column pass
+func ConvSeparablePass[T types.Real](m Matrix[T], col, row []float64, border Border[float64], size ConvSize) (General[float64], error) {
+	m1 := float64Matrix(m)
+	if m1.Empty() || len(col) == 0 || len(row) == 0 {
+		return General[float64]{}, ErrEmptyMatrix
+	}
+	p := m1.padConv(Index2{len(row), len(col)}, border, size)
+	if p.x < len(row) || p.y < len(col) {
+		return General[float64]{}, &DimensionError{
+			Op:   "ConvSeparablePass",
+			Dims: []Index2{p.Dims(), {len(row), len(col)}},
+			Why:  ErrLargeKernel,
+		}
+	}
+	t := NewGeneral[float64](p.x-len(row)+1, p.y)
+	for y := range t.y {
+		a, r := p.val[y*p.x:(y+1)*p.x], t.val[y*t.x:(y+1)*t.x]
+		for x := range r {
+			r[x] = dotFloat64(row, a[x:])
+		}
+	}
+	r := NewGeneral[float64](t.x, t.y-len(col)+1)
+	for j, c := range col {
+		axpyFloat64(r.val, t.val[j*t.x:j*t.x+len(r.val)], c)
+	}
+	return r, nil
+}
+
+// Valid convolution of m by kernel, by the convolution theorem
+func convFFT(m, kernel General[float64]) General[float64] {
+	p, _ := NewFFTPlan2(fftSize(m.x), fftSize(m.y))
+	nx, ny := p.x.n, p.y.n
+	a, b := NewGeneral[float64](nx, ny), NewGeneral[float64](nx, ny)
+	for y := range m.y {
+		copy(a.val[y*nx:], m.val[y*m.x:(y+1)*m.x])
+	}
+	// Convolution is correlation with the kernel reversed
+	for y := range kernel.y {
+		for x := range kernel.x {
+			b.val[(kernel.y-1-y)*nx+kernel.x-1-x] = kernel.val[y*kernel.x+x]
+		}
+	}
+	fa, _ := p.Real(a, NormBackward)
+	fb, _ := p.Real(b, NormBackward)
+	for i, v := range fb.val {
+		fa.val[i] *= v
+	}
+	c, _ := p.RealInverse(fa, NormBackward)
+	r := NewGeneral[float64](m.x-kernel.x+1, m.y-kernel.y+1)
+	for y := range r.y {
+		copy(r.val[y*r.x:(y+1)*r.x], c.val[(y+kernel.y-1)*nx+kernel.x-1:])
+	}
+	return r
+}
@@ -0,0 +1,74 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Check that the direct, separable and FFT convolutions of m by the separable kernel
+// col*row agree within tol, for each border and output size
+func testConvFast[T types.Real](t *testing.T, m General[T], col, row []T, tol float64) {
+	t.Helper()
+	k := NewGeneral[T](len(row), len(col))
+	for i := range k.val {
+		k.val[i] = col[i/k.x] * row[i%k.x]
+	}
+	for _, border := range []Border[float64]{{}, {Mode: PadConstant, Value: 3}, {Mode: PadReplicate}, {Mode: PadReflect}, {Mode: PadReflect101}, {Mode: PadWrap}} {
+		for _, size := range []ConvSize{ConvValid, ConvSame, ConvFull} {
+			want, err := ConvFast(m, k, border, size, ConvDirect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, method := range []ConvMethod{ConvSeparable, ConvFFT, ConvAuto} {
+				got, err := ConvFast(m, k, border, size, method)
+				if err != nil {
+					t.Fatalf("method %d, border %d, size %d: %v", method, border.Mode, size, err)
+				}
+				if got.Dims() != want.Dims() {
+					t.Fatalf("method %d, border %d, size %d: dims %v, want %v", method, border.Mode, size, got.Dims(), want.Dims())
+				}
+				for i, v := range want.val {
+					if d := math.Abs(got.val[i] - v); d > tol*max(1, math.Abs(v)) {
+						t.Fatalf("method %d, border %d, size %d: element %d is %g, want %g", method, border.Mode, size, i, got.val[i], v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConvFast(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	mi := NewGeneral[int](23, 17)
+	for i := range mi.val {
+		mi.val[i] = r.Intn(256)
+	}
+	testConvFast(t, mi, []int{1, 2, 1}, []int{-1, 0, 1}, 1e-9)
+	testConvFast(t, mi, []int{1, 4, 6, 4, 1}, []int{2, -3}, 1e-9)
+
+	mf := NewGeneral[float64](31, 20)
+	for i := range mf.val {
+		mf.val[i] = r.Float64()*2 - 1
+	}
+	g := make([]float64, 13)
+	for i := range g {
+		g[i] = math.Exp(-float64((i-6)*(i-6)) / 8)
+	}
+	testConvFast(t, mf, g, g, 1e-9)
+	testConvFast(t, mf, []float64{0.25, 0.5, 0.25}, []float64{1, -2, 1, 0.5}, 1e-9)
+
+	// Kernels of rank 2 are convolved by the other strategies alike
+	k := NewGeneral(3, 3, 1.0, 0, 2, 0, 1, 0, 2, 0, 1)
+	if _, err := ConvFast(mf, k, Border[float64]{}, ConvSame, ConvSeparable); err != ErrInseparable {
+		t.Fatal(err)
+	}
+	d, _ := ConvFast(mf, k, Border[float64]{Mode: PadReflect}, ConvSame, ConvDirect)
+	f, _ := ConvFast(mf, k, Border[float64]{Mode: PadReflect}, ConvSame, ConvFFT)
+	for i, v := range d.val {
+		if math.Abs(f.val[i]-v) > 1e-9 {
+			t.Fatal(i, f.val[i], v)
+		}
+	}
+}
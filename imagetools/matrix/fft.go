@@ -0,0 +1,544 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// Scaling convention of discrete Fourier transforms of length n
+type FFTNorm int
+
+const (
+	NormBackward FFTNorm = iota // forward unscaled, inverse scaled by 1/n
+	NormOrtho                   // both scaled by 1/sqrt(n), which makes them unitary
+	NormForward                 // forward scaled by 1/n, inverse unscaled
+)
+
+// Maximum prime factor of the length handled by the mixed-radix transform,
+// beyond which the Bluestein algorithm is used
+const fftMaxRadix = 64
+
+// Reusable plan of the fast Fourier transform of a fixed length,
+// holding its factorization and twiddle factors.
+// A plan is read-only once made, so it is safe for concurrent use.
+//
+// The forward transform is X[k] = Σ x[j] exp(-2πijk/n).
+type FFTPlan struct {
+	n       int
+	factors []int           // radices of the mixed-radix transform
+	tw      [2][]complex128 // exp(∓2πik/n) of the forward and inverse transforms
+	blue    *bluestein      // replaces factors for lengths of large prime factors
+	half    *FFTPlan        // of length n/2 for real input of even length
+}
+
+// Bluestein's chirp-z algorithm, which expresses a transform of any length
+// as a convolution of power-of-two length
+type bluestein struct {
+	plan   *FFTPlan
+	chirp  []complex128 // exp(-πik²/n)
+	filter []complex128 // spectrum of the conjugated chirp
+}
+
+// Make a plan of the FFT of length n
+func NewFFTPlan(n int) (*FFTPlan, error) {
+	if n <= 0 {
+		return nil, ErrEmptyMatrix
+	}
+	p := newFFTPlan(n)
+	if n%2 == 0 {
+		p.half = newFFTPlan(n / 2)
+	}
+	return p, nil
+}
+
+func newFFTPlan(n int) *FFTPlan {
+	p := &FFTPlan{n: n}
+	for r, m := 4, n; m > 1; m /= r {
+		for m%r != 0 {
+			switch r {
+			case 4:
+				r = 2
+			case 2:
+				r = 3
+			default:
+				r += 2
+			}
+			if r*r > m {
+				r = m
+			}
+		}
+		p.factors = append(p.factors, r)
+	}
+	p.tw[0], p.tw[1] = make([]complex128, n), make([]complex128, n)
+	for k := range n {
+		s, c := math.Sincos(-2 * math.Pi * float64(k) / float64(n))
+		p.tw[0][k], p.tw[1][k] = complex(c, s), complex(c, -s)
+	}
+	if len(p.factors) > 0 && p.factors[len(p.factors)-1] > fftMaxRadix {
+		p.factors, p.blue = nil, newBluestein(n)
+	}
+	return p
+}
+
+func newBluestein(n int) *bluestein {
+	m := 1 << bits.Len(uint(2*n-2))
+	b := &bluestein{plan: newFFTPlan(m), chirp: make([]complex128, n)}
+	f := make([]complex128, m)
+	for k := range n {
+		// k² mod 2n keeps the phase accurate for large k
+		s, c := math.Sincos(-math.Pi * float64(k*k%(2*n)) / float64(n))
+		b.chirp[k] = complex(c, s)
+		f[k], f[(m-k)%m] = complex(c, -s), complex(c, -s)
+	}
+	b.filter = make([]complex128, m)
+	b.plan.transform(b.filter, f, false)
+	return b
+}
+
+// Get the length of the transform
+func (p *FFTPlan) Len() int {
+	return p.n
+}
+
+// Scale factor of a transform of length n
+func fftScale(n int, inverse bool, norm FFTNorm) float64 {
+	switch {
+	case norm == NormOrtho:
+		return 1 / math.Sqrt(float64(n))
+	case inverse == (norm == NormBackward):
+		return 1 / float64(n)
+	}
+	return 1
+}
+
+func scaleComplex(a []complex128, s float64) {
+	if s != 1 {
+		for i := range a {
+			a[i] *= complex(s, 0)
+		}
+	}
+}
+
+func (p *FFTPlan) lenError(op string, n, want int) error {
+	return &DimensionError{
+		Op:   op,
+		Dims: []Index2{{n, 1}, {want, 1}},
+		Why:  ErrDimensions,
+	}
+}
+
+// Transform a in place by the forward FFT
+func (p *FFTPlan) Forward(a []complex128, norm FFTNorm) error {
+	if len(a) != p.n {
+		return p.lenError("FFTPlan.Forward", len(a), p.n)
+	}
+	p.transform(a, append([]complex128(nil), a...), false)
+	scaleComplex(a, fftScale(p.n, false, norm))
+	return nil
+}
+
+// Transform a in place by the inverse FFT
+func (p *FFTPlan) Inverse(a []complex128, norm FFTNorm) error {
+	if len(a) != p.n {
+		return p.lenError("FFTPlan.Inverse", len(a), p.n)
+	}
+	p.transform(a, append([]complex128(nil), a...), true)
+	scaleComplex(a, fftScale(p.n, true, norm))
+	return nil
+}
+
+// Forward FFT of real src into the n/2+1 coefficients dst,
+// the others being their conjugates, as X[n-k] = conj(X[k]).
+// Even lengths take a complex transform of half length.
+func (p *FFTPlan) Real(dst []complex128, src []float64, norm FFTNorm) error {
+	if len(src) != p.n {
+		return p.lenError("FFTPlan.Real", len(src), p.n)
+	} else if len(dst) != p.n/2+1 {
+		return p.lenError("FFTPlan.Real", len(dst), p.n/2+1)
+	}
+	p.real(dst, src)
+	scaleComplex(dst, fftScale(p.n, false, norm))
+	return nil
+}
+
+// Inverse FFT into real dst from its n/2+1 coefficients src, as given by Real.
+// The imaginary parts of the coefficients without conjugates are ignored.
+func (p *FFTPlan) RealInverse(dst []float64, src []complex128, norm FFTNorm) error {
+	if len(dst) != p.n {
+		return p.lenError("FFTPlan.RealInverse", len(dst), p.n)
+	} else if len(src) != p.n/2+1 {
+		return p.lenError("FFTPlan.RealInverse", len(src), p.n/2+1)
+	}
+	p.realInverse(dst, src)
+	if s := fftScale(p.n, true, norm); s != 1 {
+		for i := range dst {
+			dst[i] *= s
+		}
+	}
+	return nil
+}
+
+// Unnormalized transform of src into dst, which must not overlap
+func (p *FFTPlan) transform(dst, src []complex128, inverse bool) {
+	switch {
+	case p.blue != nil:
+		p.blue.transform(dst, src, inverse)
+	case len(p.factors) == 0:
+		copy(dst, src[:p.n])
+	case inverse:
+		p.work(dst[:p.n], src, 1, p.factors, p.tw[1], complex(0, 1))
+	default:
+		p.work(dst[:p.n], src, 1, p.factors, p.tw[0], complex(0, -1))
+	}
+}
+
+// Recursive decimation in time of the elements of src of the given stride into dst,
+// by the radices in factors, i4 being the twiddle factor of a quarter turn
+func (p *FFTPlan) work(dst, src []complex128, stride int, factors []int, tw []complex128, i4 complex128) {
+	r := factors[0]
+	m := len(dst) / r
+	if m == 1 {
+		for q := range r {
+			dst[q] = src[q*stride]
+		}
+	} else {
+		for q := range r {
+			p.work(dst[q*m:(q+1)*m], src[q*stride:], stride*r, factors[1:], tw, i4)
+		}
+	}
+	// exp(∓2πij/len(dst)) is tw[j*stride]
+	switch r {
+	case 2:
+		for k := range m {
+			t := dst[k+m] * tw[k*stride]
+			dst[k], dst[k+m] = dst[k]+t, dst[k]-t
+		}
+	case 4:
+		for k := range m {
+			a0, a1 := dst[k], dst[k+m]*tw[k*stride]
+			a2, a3 := dst[k+2*m]*tw[2*k*stride], dst[k+3*m]*tw[3*k*stride]
+			s0, s1, d0, d1 := a0+a2, a1+a3, a0-a2, (a1-a3)*i4
+			dst[k], dst[k+m], dst[k+2*m], dst[k+3*m] = s0+s1, d0+d1, s0-s1, d0-d1
+		}
+	default:
+		t, n := make([]complex128, r), m*stride // exp(∓2πi/r) is tw[n]
+		for k := range m {
+			for q := range t {
+				t[q] = dst[k+q*m] * tw[q*k*stride]
+			}
+			for u := range r {
+				s := t[0]
+				for q := 1; q < r; q++ {
+					s += t[q] * tw[q*u%r*n]
+				}
+				dst[k+u*m] = s
+			}
+		}
+	}
+}
+
+// Unnormalized transform by the chirp-z convolution.
+// The inverse is taken as the conjugate of the forward transform of the conjugate.
+func (b *bluestein) transform(dst, src []complex128, inverse bool) {
+	m := len(b.filter)
+	a := make([]complex128, 2*m)
+	x, y := a[:m], a[m:]
+	for k, c := range b.chirp {
+		if v := src[k]; inverse {
+			x[k] = cmplx.Conj(v) * c
+		} else {
+			x[k] = v * c
+		}
+	}
+	b.plan.transform(y, x, false)
+	for i, f := range b.filter {
+		y[i] *= f
+	}
+	b.plan.transform(x, y, true)
+	s := complex(1/float64(m), 0)
+	for k, c := range b.chirp {
+		if v := x[k] * c * s; inverse {
+			dst[k] = cmplx.Conj(v)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+// Unnormalized forward transform of real src into its n/2+1 coefficients.
+// For even n the even and odd elements are packed into a complex sequence of length n/2.
+func (p *FFTPlan) real(dst []complex128, src []float64) {
+	n := p.n
+	if p.half == nil {
+		a := make([]complex128, 2*n)
+		for i, v := range src {
+			a[n+i] = complex(v, 0)
+		}
+		p.transform(a[:n], a[n:], false)
+		copy(dst, a[:n/2+1])
+		return
+	}
+	h := n / 2
+	z := make([]complex128, 2*h)
+	for k := range h {
+		z[h+k] = complex(src[2*k], src[2*k+1])
+	}
+	p.half.transform(z[:h], z[h:], false)
+	for k := 0; k <= h; k++ {
+		zk, zc := z[k%h], cmplx.Conj(z[(h-k)%h])
+		even, odd := (zk+zc)*0.5, (zk-zc)*complex(0, -0.5)
+		dst[k] = even + p.tw[0][k]*odd
+	}
+}
+
+// Unnormalized inverse transform into real dst from its n/2+1 coefficients src
+func (p *FFTPlan) realInverse(dst []float64, src []complex128) {
+	n := p.n
+	if p.half == nil {
+		a := make([]complex128, 2*n)
+		b := a[n:]
+		copy(b, src)
+		for k := n/2 + 1; k < n; k++ {
+			b[k] = cmplx.Conj(src[n-k])
+		}
+		p.transform(a[:n], b, true)
+		for i := range dst {
+			dst[i] = real(a[i])
+		}
+		return
+	}
+	h := n / 2
+	z := make([]complex128, 2*h)
+	for k := range h {
+		x, xc := src[k], cmplx.Conj(src[h-k])
+		z[h+k] = (x + xc) + (x-xc)*p.tw[1][k]*complex(0, 1)
+	}
+	p.half.transform(z[:h], z[h:], true)
+	for k, v := range z[:h] {
+		dst[2*k], dst[2*k+1] = real(v), imag(v)
+	}
+}
+
+// Transform each column of m in place
+func (p *FFTPlan) columns(m General[complex128], inverse bool) {
+	a := make([]complex128, 2*m.y)
+	c, t := a[:m.y], a[m.y:]
+	for x := range m.x {
+		for y := range c {
+			c[y] = m.val[y*m.x+x]
+		}
+		p.transform(t, c, inverse)
+		for y, v := range t {
+			m.val[y*m.x+x] = v
+		}
+	}
+}
+
+// Transform each row of m in place
+func (p *FFTPlan) rows(m General[complex128], inverse bool) {
+	t := make([]complex128, m.x)
+	for y := range m.y {
+		r := m.val[y*m.x : (y+1)*m.x]
+		copy(t, r)
+		p.transform(r, t, inverse)
+	}
+}
+
+// Reusable plan of the 2-D FFT of matrices of fixed dimensions
+type FFTPlan2 struct {
+	x, y *FFTPlan
+}
+
+// Make a plan of the 2-D FFT of x*y matrices
+func NewFFTPlan2(x, y int) (*FFTPlan2, error) {
+	px, err := NewFFTPlan(x)
+	if err != nil {
+		return nil, err
+	}
+	py, err := NewFFTPlan(y)
+	if err != nil {
+		return nil, err
+	}
+	return &FFTPlan2{x: px, y: py}, nil
+}
+
+// Get the dimension lengths of the transformed matrices
+func (p *FFTPlan2) Dims() Index2 {
+	return Index2{p.x.n, p.y.n}
+}
+
+func (p *FFTPlan2) check(op string, d, want Index2) error {
+	if d != want {
+		return &DimensionError{Op: op, Dims: []Index2{d, want}, Why: ErrDimensions}
+	}
+	return nil
+}
+
+// 2-D forward FFT into a new matrix
+func (p *FFTPlan2) Forward(m Matrix[complex128], norm FFTNorm) (General[complex128], error) {
+	return p.apply("FFTPlan2.Forward", m, false, norm)
+}
+
+// 2-D inverse FFT into a new matrix
+func (p *FFTPlan2) Inverse(m Matrix[complex128], norm FFTNorm) (General[complex128], error) {
+	return p.apply("FFTPlan2.Inverse", m, true, norm)
+}
+
+func (p *FFTPlan2) apply(op string, m Matrix[complex128], inverse bool, norm FFTNorm) (General[complex128], error) {
+	if err := p.check(op, m.Dims(), p.Dims()); err != nil {
+		return General[complex128]{}, err
+	}
+	r := ViewOf(m).Clone()
+	p.x.rows(r, inverse)
+	p.y.columns(r, inverse)
+	scaleComplex(r.val, fftScale(r.x*r.y, inverse, norm))
+	return r, nil
+}
+
+// 2-D forward FFT of a real matrix into its x/2+1 leftmost columns of coefficients,
+// the others being conjugates, as X[x-i][y-j] = conj(X[i][j])
+func (p *FFTPlan2) Real(m Matrix[float64], norm FFTNorm) (General[complex128], error) {
+	if err := p.check("FFTPlan2.Real", m.Dims(), p.Dims()); err != nil {
+		return General[complex128]{}, err
+	}
+	v := ViewOf(m)
+	r := NewGeneral[complex128](v.x/2+1, v.y)
+	buf := make([]float64, v.x)
+	for y := range v.y {
+		p.x.real(r.val[y*r.x:(y+1)*r.x], v.line(0, y, v.x, false, buf))
+	}
+	p.y.columns(r, false)
+	scaleComplex(r.val, fftScale(v.x*v.y, false, norm))
+	return r, nil
+}
+
+// 2-D inverse FFT into a real matrix from its x/2+1 leftmost columns of coefficients,
+// as given by Real
+func (p *FFTPlan2) RealInverse(m Matrix[complex128], norm FFTNorm) (General[float64], error) {
+	if err := p.check("FFTPlan2.RealInverse", m.Dims(), Index2{p.x.n/2 + 1, p.y.n}); err != nil {
+		return General[float64]{}, err
+	}
+	c := ViewOf(m).Clone()
+	p.y.columns(c, true)
+	r := NewGeneral[float64](p.x.n, p.y.n)
+	for y := range r.y {
+		p.x.realInverse(r.val[y*r.x:(y+1)*r.x], c.val[y*c.x:(y+1)*c.x])
+	}
+	if s := fftScale(r.x*r.y, true, norm); s != 1 {
+		for i := range r.val {
+			r.val[i] *= s
+		}
+	}
+	return r, nil
+}
+
+func fftRows(m Matrix[complex128], inverse bool, norm FFTNorm) (General[complex128], error) {
+	r := ViewOf(m).Clone()
+	p, err := NewFFTPlan(r.x)
+	if err != nil {
+		return r, err
+	}
+	p.rows(r, inverse)
+	scaleComplex(r.val, fftScale(r.x, inverse, norm))
+	return r, nil
+}
+
+// 1-D forward FFT of each row of a matrix
+func FFT(m Matrix[complex128], norm FFTNorm) (General[complex128], error) {
+	return fftRows(m, false, norm)
+}
+
+// 1-D inverse FFT of each row of a matrix
+func IFFT(m Matrix[complex128], norm FFTNorm) (General[complex128], error) {
+	return fftRows(m, true, norm)
+}
+
+// 1-D forward FFT of each row of a real matrix, into the x/2+1 leftmost coefficients
+func RFFT[T types.Real](m Matrix[T], norm FFTNorm) (General[complex128], error) {
+	m1 := float64Matrix(m)
+	p, err := NewFFTPlan(m1.x)
+	if err != nil || m1.y <= 0 {
+		return General[complex128]{}, ErrEmptyMatrix
+	}
+	r := NewGeneral[complex128](m1.x/2+1, m1.y)
+	for y := range m1.y {
+		p.Real(r.val[y*r.x:(y+1)*r.x], m1.val[y*m1.x:(y+1)*m1.x], norm)
+	}
+	return r, nil
+}
+
+// 1-D inverse FFT of each row of x/2+1 leftmost coefficients, as given by RFFT,
+// into the real rows of length x
+func IRFFT(m Matrix[complex128], x int, norm FFTNorm) (General[float64], error) {
+	v := ViewOf(m)
+	p, err := NewFFTPlan(x)
+	if err != nil || v.y <= 0 {
+		return General[float64]{}, ErrEmptyMatrix
+	} else if v.x != x/2+1 {
+		return General[float64]{}, p.lenError("IRFFT", v.x, x/2+1)
+	}
+	r := NewGeneral[float64](x, v.y)
+	buf := make([]complex128, v.x)
+	for y := range r.y {
+		p.RealInverse(r.val[y*r.x:(y+1)*r.x], v.line(0, y, v.x, false, buf), norm)
+	}
+	return r, nil
+}
+
+// 2-D forward FFT
+func FFT2(m Matrix[complex128], norm FFTNorm) (General[complex128], error) {
+	d := m.Dims()
+	p, err := NewFFTPlan2(d[0], d[1])
+	if err != nil {
+		return General[complex128]{}, err
+	}
+	return p.Forward(m, norm)
+}
+
+// 2-D inverse FFT
+func IFFT2(m Matrix[complex128], norm FFTNorm) (General[complex128], error) {
+	d := m.Dims()
+	p, err := NewFFTPlan2(d[0], d[1])
+	if err != nil {
+		return General[complex128]{}, err
+	}
+	return p.Inverse(m, norm)
+}
+
+// 2-D forward FFT of a real matrix, into the x/2+1 leftmost columns of coefficients
+func RFFT2[T types.Real](m Matrix[T], norm FFTNorm) (General[complex128], error) {
+	m1 := float64Matrix(m)
+	p, err := NewFFTPlan2(m1.x, m1.y)
+	if err != nil {
+		return General[complex128]{}, err
+	}
+	return p.Real(m1, norm)
+}
+
+// 2-D inverse FFT of the x/2+1 leftmost columns of coefficients, as given by RFFT2,
+// into a real matrix with rows of length x
+func IRFFT2(m Matrix[complex128], x int, norm FFTNorm) (General[float64], error) {
+	p, err := NewFFTPlan2(x, m.Dims()[1])
+	if err != nil {
+		return General[float64]{}, err
+	}
+	return p.RealInverse(m, norm)
+}
+
+// Smallest length not less than n with no prime factors other than 2, 3 and 5,
+// for which the FFT is fastest
+func fftSize(n int) int {
+	for n = max(n, 1); ; n++ {
+		m := n
+		for _, p := range [...]int{2, 3, 5} {
+			for m%p == 0 {
+				m /= p
+			}
+		}
+		if m == 1 {
+			return n
+		}
+	}
+}
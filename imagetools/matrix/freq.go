@@ -0,0 +1,163 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+)
+
+// Shape of the transfer function of a frequency-domain filter
+type FreqShape int
+
+const (
+	FreqIdeal       FreqShape = iota // sharp cutoff, which rings in the spatial domain
+	FreqButterworth                  // 1/(1+(D/D0)^2n), sharper for higher order n
+	FreqGaussian                     // exp(-D^2/2D0^2), without ringing
+)
+
+// Frequency-domain filters are matrices of real transfer functions in the layout of the DFT,
+// of zero frequency at (0,0), which [FFTShift] moves to the center.
+// Frequencies are measured in cycles per pixel, ranging within [-0.5,0.5).
+
+// Frequency of index i of a DFT of length n, in cycles per sample,
+// of the Nyquist frequency of even n negative as by [FFTShift]
+func fftFreq(i, n int) float64 {
+	if i >= (n+1)/2 {
+		i -= n
+	}
+	return float64(i) / float64(n)
+}
+
+// Low-pass transfer function at distance d from the center frequency
+func lowPass(shape FreqShape, d, cutoff float64, order int) float64 {
+	switch shape {
+	case FreqButterworth:
+		if cutoff <= 0 {
+			return 0
+		}
+		return 1 / (1 + math.Pow(d/cutoff, 2*float64(order)))
+	case FreqGaussian:
+		if cutoff <= 0 {
+			return 0
+		}
+		return math.Exp(-d * d / (2 * cutoff * cutoff))
+	}
+	if d <= cutoff {
+		return 1
+	}
+	return 0
+}
+
+// Make a filter of dimensions (x,y) from its transfer function of frequencies (u,v)
+func freqFilter(x, y int, h func(u, v float64) float64) General[float64] {
+	m := NewGeneral[float64](x, y)
+	for j := range m.y {
+		v := fftFreq(j, y)
+		for i := range m.x {
+			m.val[j*m.x+i] = h(fftFreq(i, x), v)
+		}
+	}
+	return m
+}
+
+// Low-pass filter of dimensions (x,y), passing frequencies below cutoff.
+// order only applies to FreqButterworth.
+func LowPass(x, y int, shape FreqShape, cutoff float64, order int) General[float64] {
+	return freqFilter(x, y, func(u, v float64) float64 {
+		return lowPass(shape, math.Hypot(u, v), cutoff, order)
+	})
+}
+
+// High-pass filter of dimensions (x,y), passing frequencies above cutoff.
+// order only applies to FreqButterworth.
+func HighPass(x, y int, shape FreqShape, cutoff float64, order int) General[float64] {
+	return freqFilter(x, y, func(u, v float64) float64 {
+		return 1 - lowPass(shape, math.Hypot(u, v), cutoff, order)
+	})
+}
+
+// Band-pass filter of dimensions (x,y), passing frequencies between low and high.
+// order only applies to FreqButterworth.
+func BandPass(x, y int, shape FreqShape, low, high float64, order int) General[float64] {
+	return freqFilter(x, y, func(u, v float64) float64 {
+		d := math.Hypot(u, v)
+		return lowPass(shape, d, high, order) * (1 - lowPass(shape, d, low, order))
+	})
+}
+
+// Notch filter of dimensions (x,y), rejecting frequencies within radius
+// of each of the centers (u,v) and of their mirrors (-u,-v), as periodic noise.
+// order only applies to FreqButterworth.
+func Notch(x, y int, shape FreqShape, centers [][2]float64, radius float64, order int) General[float64] {
+	return freqFilter(x, y, func(u, v float64) float64 {
+		h := 1.0
+		for _, c := range centers {
+			h *= 1 - lowPass(shape, math.Hypot(u-c[0], v-c[1]), radius, order)
+			h *= 1 - lowPass(shape, math.Hypot(u+c[0], v+c[1]), radius, order)
+		}
+		return h
+	})
+}
+
+// Filter a real matrix in the frequency domain by the transfer function h,
+// of the same dimensions and in the layout of the DFT.
+// h must be symmetric, as h(u,v) = h(-u,-v), for which the result is real.
+func FilterFreq[T types.Real](m Matrix[T], h Matrix[float64]) (General[float64], error) {
+	d := m.Dims()
+	if d != h.Dims() {
+		return General[float64]{}, &DimensionError{
+			Op:   "FilterFreq",
+			Dims: []Index2{d, h.Dims()},
+			Why:  ErrDimensions,
+		}
+	}
+	p, err := NewFFTPlan2(d[0], d[1])
+	if err != nil {
+		return General[float64]{}, err
+	}
+	f, err := p.Real(float64Matrix(m), NormBackward)
+	if err != nil {
+		return General[float64]{}, err
+	}
+	v := ViewOf(h)
+	for y := range f.y {
+		for x := range f.x {
+			f.val[y*f.x+x] *= complex(v.at(x, y), 0)
+		}
+	}
+	return p.RealInverse(f, NormBackward)
+}
+
+// Rotate a matrix by half its dimensions, so that (0,0) moves to (x/2,y/2),
+// the center of the spectrum
+func FFTShift[T types.Number](m Matrix[T]) General[T] {
+	d := m.Dims()
+	return rotateMatrix(m, d[0]/2, d[1]/2)
+}
+
+// Inverse of [FFTShift], which moves (x/2,y/2) back to (0,0)
+func IFFTShift[T types.Number](m Matrix[T]) General[T] {
+	d := m.Dims()
+	return rotateMatrix(m, (d[0]+1)/2, (d[1]+1)/2)
+}
+
+// Rotate a matrix cyclically, so that (0,0) moves to (dx,dy)
+func rotateMatrix[T types.Number](m Matrix[T], dx, dy int) General[T] {
+	v := ViewOf(m)
+	if v.Empty() {
+		return General[T]{}
+	}
+	r := NewGeneral[T](v.x, v.y)
+	for p, t := range v.Range(1, 1) {
+		r.val[(p[1]+dy)%r.y*r.x+(p[0]+dx)%r.x] = t
+	}
+	return r
+}
+
+// Visualize the magnitude of the spectrum of a matrix, centered and log-scaled
+func MagnitudeSpectrum[T types.Real](m Matrix[T]) (General[uint8], error) {
+	f, err := DFT(float64Matrix(m))
+	if err != nil {
+		return General[uint8]{}, err
+	}
+	return LogAbsolutize(FFTShift(GetAbs(*f))), nil
+}
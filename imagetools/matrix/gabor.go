@@ -0,0 +1,136 @@
+package matrix
+
+import (
+	"image"
+	types "imagetools/types"
+	"math"
+)
+
+// Bank of Gabor filters of evenly spaced orientations and octave-spaced scales,
+// each a pair of even (cosine) and odd (sine) kernels
+type GaborBank struct {
+	Thetas      []float64 // orientations in radians
+	Wavelengths []float64 // in pixels, of each scale
+	Gamma       float64   // aspect ratio of the Gaussian envelope
+	even, odd   [][]General[float64]
+}
+
+// Make a bank of n orientations in [0,π) and m scales of wavelengths doubling
+// from wavelength. The envelopes have a bandwidth of 1 octave, of sigma 0.56 wavelength.
+func NewGaborBank(n, m int, wavelength, gamma float64) (GaborBank, error) {
+	if n <= 0 || m <= 0 || !(wavelength >= 2) || !(gamma > 0) {
+		return GaborBank{}, ErrOutOfBounds
+	}
+	b := GaborBank{Gamma: gamma}
+	for i := range n {
+		b.Thetas = append(b.Thetas, math.Pi*float64(i)/float64(n))
+	}
+	for j := range m {
+		b.Wavelengths = append(b.Wavelengths, wavelength*math.Exp2(float64(j)))
+	}
+	b.even, b.odd = make([][]General[float64], m), make([][]General[float64], m)
+	for j, l := range b.Wavelengths {
+		for _, t := range b.Thetas {
+			e := GaborKernel(0.56*l, t, 1/l, 0, gamma)
+			// Without DC, so that the response does not depend on the brightness
+			s := 0.0
+			for _, v := range e.val {
+				s += v
+			}
+			g := GaborKernel(0.56*l, t, 0, 0, gamma)
+			gs := 0.0
+			for _, v := range g.val {
+				gs += v
+			}
+			for i := range e.val {
+				e.val[i] -= s / gs * g.val[i]
+			}
+			b.even[j] = append(b.even[j], e)
+			b.odd[j] = append(b.odd[j], GaborKernel(0.56*l, t, 1/l, -math.Pi/2, gamma))
+		}
+	}
+	return b, nil
+}
+
+// Get the kernels of scale j and orientation i
+func (b GaborBank) Kernels(j, i int) (even, odd General[float64]) {
+	return b.even[j][i], b.odd[j][i]
+}
+
+// Gabor energy features of a matrix, the magnitude of the responses to the even and odd
+// kernels of each scale and orientation, by Conv with the borders reflected.
+// The feature tensor is of shape [scales orientations height width],
+// whose Plane(j, i) is the feature map of scale j and orientation i.
+func GaborFeatures[T types.Real](m Matrix[T], b GaborBank) (Tensor[float64], error) {
+	m1 := float64Matrix(m)
+	if m1.Empty() || len(b.even) == 0 {
+		return Tensor[float64]{}, ErrEmptyMatrix
+	}
+	border := Border[float64]{Mode: PadReflect}
+	planes := make([]Tensor[float64], 0, len(b.Wavelengths)*len(b.Thetas))
+	for j := range b.even {
+		for i := range b.even[j] {
+			e, err := m1.FilterPad(b.even[j][i], border)
+			if err != nil {
+				return Tensor[float64]{}, err
+			}
+			o, err := m1.FilterPad(b.odd[j][i], border)
+			if err != nil {
+				return Tensor[float64]{}, err
+			}
+			for k, v := range o.val {
+				e.val[k] = math.Hypot(e.val[k], v)
+			}
+			planes = append(planes, TensorOf(e))
+		}
+	}
+	t, err := Stack(planes...)
+	if err != nil {
+		return Tensor[float64]{}, err
+	}
+	return t.Reshape(len(b.even), len(b.Thetas), m1.y, m1.x)
+}
+
+// Summary statistics of the features over a region
+type RegionStats struct {
+	Mean     []float64        // of each feature
+	Variance []float64        // of each feature
+	CoV      General[float64] // covariances between the features
+}
+
+// Statistics of the features of a tensor of shape [... height width] over region r,
+// the leading axes flattened into features in row-major order
+func FeatureStats(t Tensor[float64], r image.Rectangle) (RegionStats, error) {
+	n := t.Rank()
+	if n < 2 {
+		return RegionStats{}, &ShapeError{Op: "FeatureStats", Shapes: [][]int{t.Shape()}, Why: ErrDimensions}
+	}
+	h, w := t.shape[n-2], t.shape[n-1]
+	if r = r.Intersect(image.Rect(0, 0, w, h)); r.Empty() {
+		return RegionStats{}, ErrEmptyMatrix
+	}
+	f, err := t.Reshape(-1, h, w)
+	if err != nil {
+		return RegionStats{}, err
+	}
+	k := f.shape[0]
+	xs := make([][]float64, k)
+	for i := range xs {
+		p, _ := f.Plane(i)
+		v, _ := p.SubMatrix(r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+		xs[i] = v.Clone().val
+	}
+	s := RegionStats{
+		Mean:     make([]float64, k),
+		Variance: make([]float64, k),
+		CoV:      NewGeneral[float64](k, k),
+	}
+	for i, x := range xs {
+		s.Mean[i], s.Variance[i] = Mean(1, x...), Variance(false, x...)
+		for j := range i + 1 {
+			c := CoV(false, x, xs[j])
+			s.CoV.val[i*k+j], s.CoV.val[j*k+i] = c, c
+		}
+	}
+	return s, nil
+}
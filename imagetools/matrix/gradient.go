@@ -0,0 +1,164 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+)
+
+// Gradient of a matrix by the derivative kernels kx along x and ky along y,
+// as Sobel[2] and Sobel[0], into the magnitude and the angle in radians
+// of atan2(gy, gx), y increasing downward. The borders are replicated.
+func EdgeGradientXY[T types.Real, K types.Real](m Matrix[T], kx, ky Matrix[K]) (mag, angle General[float64], err error) {
+	b := Border[float64]{Mode: PadReplicate}
+	m1 := float64Matrix(m)
+	gx, err := ConvFast(m1, float64Matrix(kx), b, ConvSame, ConvAuto)
+	if err != nil {
+		return
+	}
+	gy, err := ConvFast(m1, float64Matrix(ky), b, ConvSame, ConvAuto)
+	if err != nil {
+		return
+	}
+	mag, angle = gradientPolar(gx, gy)
+	return mag, angle, nil
+}
+
+// Gradient of a matrix by a kernel set as Sobel, Prewitt or Roberts,
+// into the magnitude and the angle in radians of atan2(gy, gx), y increasing downward.
+//
+// Sets of 4 kernels are of the derivatives along y, a diagonal, x and the other diagonal.
+// Sets of 2 kernels are of the derivatives along the diagonals, as Roberts,
+// which are rotated back to x and y.
+func EdgeGradient[T types.Real, K types.Real](m Matrix[T], kernels []Matrix[K]) (mag, angle General[float64], err error) {
+	switch len(kernels) {
+	case 4:
+		return EdgeGradientXY(m, kernels[2], kernels[0])
+	case 2:
+		b := Border[float64]{Mode: PadReplicate}
+		m1 := float64Matrix(m)
+		// g1 = f(x,y)-f(x+1,y+1) = -(fx+fy), g2 = f(x+1,y)-f(x,y+1) = fx-fy
+		g1, err := ConvFast(m1, float64Matrix(kernels[0]), b, ConvSame, ConvAuto)
+		if err != nil {
+			return mag, angle, err
+		}
+		g2, err := ConvFast(m1, float64Matrix(kernels[1]), b, ConvSame, ConvAuto)
+		if err != nil {
+			return mag, angle, err
+		}
+		for i, s := range g1.val {
+			t := g2.val[i]
+			g1.val[i], g2.val[i] = (t-s)/2, -(s+t)/2
+		}
+		mag, angle = gradientPolar(g1, g2)
+		return mag, angle, nil
+	}
+	return mag, angle, &DimensionError{
+		Op:   "EdgeGradient",
+		Dims: []Index2{{len(kernels), 1}},
+		Why:  ErrDimensions,
+	}
+}
+
+func gradientPolar(gx, gy General[float64]) (mag, angle General[float64]) {
+	mag, angle = NewGeneral[float64](gx.x, gx.y), NewGeneral[float64](gx.x, gx.y)
+	for i, x := range gx.val {
+		mag.val[i], angle.val[i] = math.Hypot(x, gy.val[i]), math.Atan2(gy.val[i], x)
+	}
+	return mag, angle
+}
+
+// Thresholds of hysteresis from the histogram of 64 bins of the gradient magnitude,
+// high above 70% of the pixels and low at 40% of high
+func CannyThresholds(mag Matrix[float64]) (low, high float64) {
+	const bins = 64
+	top := Max(mag)
+	if !(top > 0) {
+		return 0, 0
+	}
+	var h [bins]int
+	for _, t := range elements(mag) {
+		h[min(int(t/top*bins), bins-1)]++
+	}
+	d, n := mag.Dims(), 0
+	for i, c := range h {
+		if n += c; float64(n) >= 0.7*float64(d[0]*d[1]) {
+			high = float64(i+1) / bins * top
+			break
+		}
+	}
+	return 0.4 * high, high
+}
+
+// Canny edge detection, of 255 on the edges and 0 elsewhere.
+//
+// The matrix is smoothed by the Gaussian of standard deviation sigma,
+// the Sobel gradient magnitude is thinned by non-maximum suppression along the gradient,
+// and the edges are the pixels above high, together with those above low
+// connected to them. Non-positive high selects both thresholds by [CannyThresholds].
+func Canny[T types.Real](m Matrix[T], sigma, low, high float64) (General[uint8], error) {
+	m1 := float64Matrix(m)
+	if m1.Empty() {
+		return General[uint8]{}, ErrEmptyMatrix
+	}
+	if sigma > 0 {
+		var err error
+		if m1, err = ConvFast(m1, GaussianPSF(sigma), Border[float64]{Mode: PadReplicate}, ConvSame, ConvAuto); err != nil {
+			return General[uint8]{}, err
+		}
+	}
+	mag, angle, err := EdgeGradient(m1, Sobel)
+	if err != nil {
+		return General[uint8]{}, err
+	}
+	if high <= 0 {
+		low, high = CannyThresholds(mag)
+	}
+	thin := suppressNonMax(mag, angle)
+
+	// Hysteresis, tracing the weak edges from the strong ones
+	r := NewGeneral[uint8](m1.x, m1.y)
+	var stack []int
+	for i, t := range thin.val {
+		if t >= high && t > 0 {
+			r.val[i] = 255
+			stack = append(stack, i)
+		}
+	}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := i%r.x, i/r.x
+		for j := max(y-1, 0); j <= min(y+1, r.y-1); j++ {
+			for k := max(x-1, 0); k <= min(x+1, r.x-1); k++ {
+				if n := j*r.x + k; r.val[n] == 0 && thin.val[n] >= low && thin.val[n] > 0 {
+					r.val[n] = 255
+					stack = append(stack, n)
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+// Keep the gradient magnitude only where it is maximal along the gradient,
+// of the angle quantized to 45°
+func suppressNonMax(mag, angle General[float64]) General[float64] {
+	r := NewGeneral[float64](mag.x, mag.y)
+	at := func(x, y int) float64 {
+		if x < 0 || x >= mag.x || y < 0 || y >= mag.y {
+			return 0
+		}
+		return mag.val[y*mag.x+x]
+	}
+	for i, t := range mag.val {
+		x, y := i%mag.x, i/mag.x
+		// Direction of the gradient among 0°, 45°, 90° and 135°
+		d := int(math.Round(angle.val[i]/(math.Pi/4))) & 3
+		dx, dy := [4]int{1, 1, 0, -1}[d], [4]int{0, 1, 1, 1}[d]
+		// Ties broken toward one side, to keep plateaus one pixel thick
+		if t > at(x-dx, y-dy) && t >= at(x+dx, y+dy) {
+			r.val[i] = t
+		}
+	}
+	return r
+}
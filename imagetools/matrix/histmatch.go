@@ -0,0 +1,133 @@
+package matrix
+
+import (
+	"image"
+	"image/color"
+	types "imagetools/types"
+	"math"
+)
+
+// Map the values v of the histogram src onto the distribution of target,
+// of the values of integer types at the centers of their unit bins by the offset 0.5
+func specify(v General[float64], src, target Histogram, offset float64) General[float64] {
+	cs, ct := src.CDF(), target.CDF()
+	return MapMatrix(v, func(t float64) float64 {
+		return target.quantile(ct, src.cdfAt(cs, t+offset)) - offset
+	})
+}
+
+// Histogram specification of a matrix, mapping its values monotonically onto the
+// distribution of the 1-D histogram target, such as of [HistogramFromCDF], the histogram
+// of m being as [HistogramOf] of the bins of target
+func SpecifyHistogram[T types.Real](m Matrix[T], target Histogram) (General[T], error) {
+	if len(target.Min) != 1 {
+		return General[T]{}, &ShapeError{Op: "SpecifyHistogram", Shapes: [][]int{target.Counts.shape}, Why: ErrDimensions}
+	}
+	g := float64Matrix(m)
+	if g.Empty() {
+		return General[T]{}, ErrEmptyMatrix
+	}
+	offset := 0.0
+	if isInteger[T]() {
+		offset = 0.5
+	}
+	src := HistogramOf(m, target.Counts.shape[0])
+	lo, hi := integerRange[T]()
+	return MapMatrix(specify(g, src, target, offset), func(t float64) T { return saturate(t, lo, hi) }), nil
+}
+
+// Histogram matching of a matrix to the distribution of the values of ref,
+// of the histograms as [HistogramOf] of the bins, 256 if non-positive
+func MatchHistogram[T types.Real](m, ref Matrix[T], bins int) (General[T], error) {
+	return SpecifyHistogram(m, HistogramOf(ref, bins))
+}
+
+// Options of the histogram matching of images
+type HistogramMatchOptions struct {
+	Space       ColorSpace // of the channels matched, of D65, gamma-encoded sRGB if zero
+	Decorrelate bool       // matching along the principal axes of the colors of the reference in Space
+	Bins        int        // of the histograms of the channels, 1024 if non-positive
+}
+
+// Histogram matching of the colors of an image to those of ref, such as of the halves
+// of a stereo pair before block matching, each channel in 16 bits or more matched
+// over the range of both images, of the alpha divided out and preserved
+func MatchImageHistogram(m, ref image.Image, o HistogramMatchOptions) (*image.NRGBA64, error) {
+	if m == nil || m.Bounds().Empty() || ref == nil || ref.Bounds().Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	bins := o.Bins
+	if bins <= 0 {
+		bins = 1024
+	}
+	p, q := ImageToSpace(m, o.Space, D65), ImageToSpace(ref, o.Space, D65)
+
+	// Orthonormal axes of the matching, the principal axes of the colors of ref if decorrelated
+	axes := IdentityGeneral[float64](3)
+	if o.Decorrelate {
+		mean := [3]float64{}
+		for k := range q {
+			for _, t := range q[k].val {
+				mean[k] += t
+			}
+			mean[k] /= float64(len(q[k].val))
+		}
+		cov := NewGeneral[float64](3, 3)
+		for i := range q[0].val {
+			for r := range 3 {
+				for c := range 3 {
+					cov.val[r*3+c] += (q[r].val[i] - mean[r]) * (q[c].val[i] - mean[c])
+				}
+			}
+		}
+		_, v, err := EigenSym(cov)
+		if err != nil {
+			return nil, err
+		}
+		axes = v
+	}
+	// Coordinates of the colors along the axes, or back from them by the transpose
+	project := func(p [3]General[float64], back bool) [3]General[float64] {
+		var r [3]General[float64]
+		for k := range r {
+			r[k] = NewGeneral[float64](p[0].x, p[0].y)
+		}
+		for i := range p[0].val {
+			for k := range 3 {
+				s := 0.0
+				for c := range 3 {
+					if back {
+						s += axes.val[k*3+c] * p[c].val[i]
+					} else {
+						s += axes.val[c*3+k] * p[c].val[i]
+					}
+				}
+				r[k].val[i] = s
+			}
+		}
+		return r
+	}
+	p, q = project(p, false), project(q, false)
+
+	for k := range p {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, g := range []General[float64]{p[k], q[k]} {
+			l, h := histogramRange(g)
+			lo, hi = min(lo, l), max(hi, h)
+		}
+		src, _ := HistogramRange(p[k], bins, lo, hi)
+		target, _ := HistogramRange(q[k], bins, lo, hi)
+		p[k] = specify(p[k], src, target, 0)
+	}
+	p = project(p, true)
+
+	b := m.Bounds()
+	r := image.NewNRGBA64(b)
+	for i := range p[0].val {
+		x, y := b.Min.X+i%b.Dx(), b.Min.Y+i/b.Dx()
+		c := FromSpace([3]float64{p[0].val[i], p[1].val[i], p[2].val[i]}, o.Space, D65)
+		c.A = color.NRGBA64Model.Convert(m.At(x, y)).(color.NRGBA64).A
+		r.SetNRGBA64(x, y, c)
+	}
+	return r, nil
+}
@@ -0,0 +1,412 @@
+package matrix
+
+import (
+	"image"
+	"image/color"
+	types "imagetools/types"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Histogram of bins of equal widths over [Min[d], Max[d]] along each dimension d,
+// of the last bins including Max. The counts are in a tensor of the shape of the bins,
+// as 1-D histograms of the values of a matrix or channel, or joint 2-D and 3-D ones
+// of several channels.
+type Histogram struct {
+	Counts   Tensor[float64]
+	Min, Max []float64
+}
+
+// Empty histogram of the bins over [lo[d], hi[d]] along each dimension d
+func NewHistogram(bins []int, lo, hi []float64) (Histogram, error) {
+	if len(bins) == 0 || len(lo) != len(bins) || len(hi) != len(bins) {
+		return Histogram{}, &ShapeError{
+			Op:     "NewHistogram",
+			Shapes: [][]int{bins, {len(lo)}, {len(hi)}},
+			Why:    ErrDimensions,
+		}
+	}
+	for d, n := range bins {
+		if n <= 0 || !(lo[d] < hi[d]) {
+			return Histogram{}, &ShapeError{Op: "NewHistogram", Shapes: [][]int{bins}, Why: ErrOutOfBounds}
+		}
+	}
+	return Histogram{Counts: NewTensor[float64](bins), Min: slices.Clone(lo), Max: slices.Clone(hi)}, nil
+}
+
+// Range of the histograms of the values of type T, [lo, hi+1] of the whole type
+// if integer, so that 256 bins of uint8 are of a value each, or [min, max] of m otherwise
+func histogramRange[T types.Real](m Matrix[T]) (lo, hi float64) {
+	if isInteger[T]() {
+		l, h := integerRange[T]()
+		return float64(l), float64(h) + 1
+	}
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range elements(m) {
+		if t := float64(v); !math.IsNaN(t) {
+			lo, hi = min(lo, t), max(hi, t)
+		}
+	}
+	if lo > hi {
+		return 0, 1
+	} else if lo == hi {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// Histogram of the values of a matrix of the given number of bins, 256 if non-positive,
+// over the whole range of integer types, so that the bins are of the same values for
+// all matrices of the type, or over the range of the values of floating-point ones
+func HistogramOf[T types.Real](m Matrix[T], bins int) Histogram {
+	lo, hi := histogramRange(m)
+	h, _ := HistogramRange(m, bins, lo, hi)
+	return h
+}
+
+// Histogram of the values of a matrix in [lo, hi] of the given number of bins,
+// 256 if non-positive, the values out of the range ignored
+func HistogramRange[T types.Real](m Matrix[T], bins int, lo, hi float64) (Histogram, error) {
+	if bins <= 0 {
+		bins = 256
+	}
+	h, err := NewHistogram([]int{bins}, []float64{lo}, []float64{hi})
+	if err != nil {
+		return Histogram{}, err
+	}
+	for _, v := range elements(m) {
+		if i := h.bin(0, float64(v)); i >= 0 {
+			h.Counts.val[i]++
+		}
+	}
+	return h, nil
+}
+
+// Joint histogram of the values of matrices of the same dimensions, of the bins
+// over [lo[d], hi[d]] of each matrix d, or of the ranges of [HistogramOf] if lo and hi are nil
+func JointHistogram[T types.Real](ms []Matrix[T], bins []int, lo, hi []float64) (Histogram, error) {
+	gs := make([]View[T], len(ms))
+	for d, m := range ms {
+		if gs[d] = ViewOf(m); gs[d].Dims() != gs[0].Dims() {
+			return Histogram{}, &DimensionError{
+				Op:   "JointHistogram",
+				Dims: []Index2{gs[0].Dims(), gs[d].Dims()},
+				Why:  ErrDimensions,
+			}
+		}
+	}
+	if lo == nil && hi == nil {
+		lo, hi = make([]float64, len(gs)), make([]float64, len(gs))
+		for d, g := range gs {
+			lo[d], hi[d] = histogramRange(g)
+		}
+	}
+	h, err := NewHistogram(bins, lo, hi)
+	if err != nil {
+		return Histogram{}, err
+	}
+	v := make([]float64, len(gs))
+	for i := range gs[0].x * gs[0].y {
+		for d, g := range gs {
+			v[d] = float64(g.at(i%g.x, i/g.x))
+		}
+		h.Add(1, v...)
+	}
+	return h, nil
+}
+
+// Histograms of the R, G, B and A channels of an image, of the alpha divided out,
+// in 16 bits over [0, 65536] of the given number of bins, 256 if non-positive
+func ImageHistograms(m image.Image, bins int) [4]Histogram {
+	var hs [4]Histogram
+	if bins <= 0 {
+		bins = 256
+	}
+	for k := range hs {
+		hs[k], _ = NewHistogram([]int{bins}, []float64{0}, []float64{0x10000})
+	}
+	if m == nil {
+		return hs
+	}
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(m.At(x, y)).(color.NRGBA64)
+			for k, v := range [4]uint16{c.R, c.G, c.B, c.A} {
+				hs[k].Counts.val[hs[k].bin(0, float64(v))]++
+			}
+		}
+	}
+	return hs
+}
+
+// Joint histogram of the first len(bins) channels of R, G and B of an image,
+// of the alpha divided out, in 16 bits over [0, 65536], such as of R and G for 2 bins
+func ColorHistogram(m image.Image, bins ...int) (Histogram, error) {
+	if len(bins) > 3 {
+		return Histogram{}, &ShapeError{Op: "ColorHistogram", Shapes: [][]int{bins}, Why: ErrDimensions}
+	}
+	lo, hi := make([]float64, len(bins)), make([]float64, len(bins))
+	for d := range hi {
+		hi[d] = 0x10000
+	}
+	h, err := NewHistogram(bins, lo, hi)
+	if err != nil || m == nil {
+		return h, err
+	}
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(m.At(x, y)).(color.NRGBA64)
+			h.Add(1, []float64{float64(c.R), float64(c.G), float64(c.B)}[:len(bins)]...)
+		}
+	}
+	return h, nil
+}
+
+// Bin of the value v along the dimension d, or -1 if out of the range
+func (h Histogram) bin(d int, v float64) int {
+	if !(v >= h.Min[d] && v <= h.Max[d]) {
+		return -1
+	}
+	n := h.Counts.shape[d]
+	return min(int((v-h.Min[d])/(h.Max[d]-h.Min[d])*float64(n)), n-1)
+}
+
+// Add the weight w to the bin of the point v of the dimensions of the histogram,
+// false if v is out of the range
+func (h Histogram) Add(w float64, v ...float64) bool {
+	if len(v) != len(h.Min) {
+		return false
+	}
+	k := 0
+	for d, t := range v {
+		i := h.bin(d, t)
+		if i < 0 {
+			return false
+		}
+		k += i * h.Counts.stride[d]
+	}
+	h.Counts.val[k] += w
+	return true
+}
+
+// Number of the bins along each dimension
+func (h Histogram) Bins() []int {
+	return h.Counts.Shape()
+}
+
+// Lower edge of the bin i along the dimension d, the upper edge of the bin i-1
+func (h Histogram) Edge(d, i int) float64 {
+	return h.Min[d] + float64(i)*(h.Max[d]-h.Min[d])/float64(h.Counts.shape[d])
+}
+
+// Center of the bin i along the dimension d
+func (h Histogram) Center(d, i int) float64 {
+	return (h.Edge(d, i) + h.Edge(d, i+1)) / 2
+}
+
+// Sum of the counts
+func (h Histogram) Total() float64 {
+	t := 0.0
+	for _, v := range h.Counts.val {
+		t += v
+	}
+	return t
+}
+
+// Copy of the histogram
+func (h Histogram) Clone() Histogram {
+	return Histogram{Counts: h.Counts.Clone(), Min: slices.Clone(h.Min), Max: slices.Clone(h.Max)}
+}
+
+// Copy of the histogram of the counts of sum 1, or of 0 if empty
+func (h Histogram) Normalize() Histogram {
+	r := h.Clone()
+	if t := h.Total(); t != 0 {
+		for i := range r.Counts.val {
+			r.Counts.val[i] /= t
+		}
+	}
+	return r
+}
+
+// 1-D histogram along the dimension d, of the counts summed over the other dimensions
+func (h Histogram) Marginal(d int) Histogram {
+	n, s := h.Counts.shape[d], h.Counts.stride[d]
+	r, _ := NewHistogram([]int{n}, h.Min[d:d+1], h.Max[d:d+1])
+	for k, v := range h.Counts.val {
+		r.Counts.val[k/s%n] += v
+	}
+	return r
+}
+
+// Cumulative distribution of a 1-D histogram, of the fractions of the total up to
+// and including each bin, or of the marginal along the first dimension of the others
+func (h Histogram) CDF() []float64 {
+	if len(h.Min) > 1 {
+		h = h.Marginal(0)
+	}
+	c := make([]float64, len(h.Counts.val))
+	t := 0.0
+	for i, v := range h.Counts.val {
+		t += v
+		c[i] = t
+	}
+	if t != 0 {
+		for i := range c {
+			c[i] /= t
+		}
+	}
+	return c
+}
+
+// Cumulative distribution at the value t along the first dimension, of the counts
+// spread evenly within the bins, as [Histogram.CDF] at the upper edges of the bins
+func (h Histogram) CDFAt(t float64) float64 {
+	return h.cdfAt(h.CDF(), t)
+}
+
+// Cumulative distribution c of h at t
+func (h Histogram) cdfAt(c []float64, t float64) float64 {
+	b := h.bin(0, t)
+	if b < 0 {
+		if t < h.Min[0] {
+			return 0
+		}
+		return c[len(c)-1]
+	}
+	lo := 0.0
+	if b > 0 {
+		lo = c[b-1]
+	}
+	w := h.Edge(0, 1) - h.Edge(0, 0)
+	return lo + (t-h.Edge(0, b))/w*(c[b]-lo)
+}
+
+// Value of the cumulative distribution p in [0, 1] along the first dimension, the inverse
+// of [Histogram.CDFAt] over the non-empty bins, or Min for empty histograms
+func (h Histogram) Quantile(p float64) float64 {
+	return h.quantile(h.CDF(), p)
+}
+
+// Value of the cumulative distribution c of h at p
+func (h Histogram) quantile(c []float64, p float64) float64 {
+	p = min(max(p, 0), 1)
+	b := sort.Search(len(c), func(i int) bool { return c[i] >= p && c[i] > 0 })
+	if b == len(c) {
+		return h.Min[0]
+	}
+	lo := 0.0
+	if b > 0 {
+		lo = c[b-1]
+	}
+	return h.Edge(0, b) + (p-lo)/(c[b]-lo)*(h.Edge(0, b+1)-h.Edge(0, b))
+}
+
+// 1-D histogram over [lo, hi] of the cumulative distribution cdf of a bin each,
+// non-decreasing, such as of a target of [SpecifyHistogram]
+func HistogramFromCDF(cdf []float64, lo, hi float64) (Histogram, error) {
+	h, err := NewHistogram([]int{len(cdf)}, []float64{lo}, []float64{hi})
+	if err != nil {
+		return Histogram{}, err
+	}
+	prev := 0.0
+	for i, c := range cdf {
+		if !(c >= prev) {
+			return Histogram{}, ErrOutOfBounds
+		}
+		h.Counts.val[i], prev = c-prev, c
+	}
+	return h, nil
+}
+
+// Metric of the comparison of histograms, of the histograms normalized to sum 1
+type HistogramMetric int
+
+const (
+	HistChiSquare     HistogramMetric = iota // symmetric χ², Σ(p-q)²/(p+q), in [0, 2]
+	HistBhattacharyya                        // Bhattacharyya distance as the Hellinger distance √(1-Σ√(pq)), in [0, 1]
+	HistEMD                                  // earth mover's distance in bins of 1-D histograms, of the sum over the marginals of the others
+	HistIntersection                         // Σmin(p,q), in [0, 1], 1 for the same distributions
+)
+
+// Compare the histogram with g of the same bins by the metric, 0 for the same distributions
+// but by HistIntersection. The EMD of joint histograms is of the sum over the marginals,
+// a lower bound of that of the L1 ground distance.
+func (h Histogram) Compare(g Histogram, metric HistogramMetric) (float64, error) {
+	if !slices.Equal(h.Counts.shape, g.Counts.shape) {
+		return 0, &ShapeError{Op: "Compare", Shapes: [][]int{h.Counts.shape, g.Counts.shape}, Why: ErrDimensions}
+	}
+	p, q := h.Normalize().Counts.val, g.Normalize().Counts.val
+	r := 0.0
+	switch metric {
+	case HistChiSquare:
+		for i := range p {
+			if s := p[i] + q[i]; s > 0 {
+				r += (p[i] - q[i]) * (p[i] - q[i]) / s
+			}
+		}
+	case HistBhattacharyya:
+		for i := range p {
+			r += math.Sqrt(p[i] * q[i])
+		}
+		r = math.Sqrt(max(1-r, 0))
+	case HistEMD:
+		for d := range h.Min {
+			c, e := h.Marginal(d).CDF(), g.Marginal(d).CDF()
+			for i := range c {
+				r += math.Abs(c[i] - e[i])
+			}
+		}
+	case HistIntersection:
+		for i := range p {
+			r += min(p[i], q[i])
+		}
+	default:
+		return 0, ErrOutOfBounds
+	}
+	return r, nil
+}
+
+// Image of width*height of the histogram in the color c over transparent, scaled to the
+// largest count, of the bars of 1-D histograms from the bottom, or of the cells of 2-D ones
+// of the first dimension along x and the second from the bottom, of c faded by the counts.
+// The images of the histograms of channels can be overlaid by [draw.Over].
+func (h Histogram) Image(width, height int, c color.Color) (*image.RGBA64, error) {
+	if width <= 0 || height <= 0 {
+		return nil, &DimensionError{Op: "Image", Dims: []Index2{{width, height}}, Why: ErrOutOfBounds}
+	}
+	if len(h.Min) == 0 || len(h.Min) > 2 {
+		return nil, &ShapeError{Op: "Image", Shapes: [][]int{h.Counts.shape}, Why: ErrDimensions}
+	}
+	m := image.NewRGBA64(image.Rect(0, 0, width, height))
+	top := slices.Max(h.Counts.val)
+	if top <= 0 {
+		return m, nil
+	}
+	r, g, b, a := c.RGBA()
+	fade := func(f float64) color.RGBA64 {
+		s := func(t uint32) uint16 { return uint16(math.Round(float64(t) * f)) }
+		return color.RGBA64{s(r), s(g), s(b), s(a)}
+	}
+	nx := h.Counts.shape[0]
+	if len(h.Min) == 1 {
+		for x := range width {
+			bar := int(math.Round(h.Counts.val[x*nx/width] / top * float64(height)))
+			for y := height - bar; y < height; y++ {
+				m.SetRGBA64(x, y, fade(1))
+			}
+		}
+		return m, nil
+	}
+	ny := h.Counts.shape[1]
+	for y := range height {
+		j := (height - 1 - y) * ny / height
+		for x := range width {
+			m.SetRGBA64(x, y, fade(h.Counts.val[x*nx/width*ny+j]/top))
+		}
+	}
+	return m, nil
+}
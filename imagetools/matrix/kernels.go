@@ -0,0 +1,196 @@
+package matrix
+
+import (
+	"math"
+	"slices"
+)
+
+// Radius of a kernel covering radius r, at least 0
+func kernelRadius(r float64) int {
+	return max(int(math.Ceil(r)), 0)
+}
+
+// Outer product of a column and a row, as a separable kernel
+func outerKernel(col, row []float64) General[float64] {
+	m := NewGeneral[float64](len(row), len(col))
+	for j, c := range col {
+		for i, r := range row {
+			m.val[j*m.x+i] = c * r
+		}
+	}
+	return m
+}
+
+// Samples of the derivative of the given order of the Gaussian of standard deviation sigma,
+// at [-r,r], the Gaussian itself normalized to sum 1.
+// The derivatives are He_n(x/σ)·(-1/σ)^n times the Gaussian, of the Hermite polynomials
+// He_0 = 1, He_1 = x, He_n+1 = x·He_n - n·He_n-1.
+func gaussian1D(sigma float64, order, r int) []float64 {
+	g := make([]float64, 2*r+1)
+	if !(sigma > 0) {
+		if order == 0 {
+			g[r] = 1
+		}
+		return g
+	}
+	s := 0.0
+	for i := range g {
+		g[i] = Gauss(float64(i-r) / sigma)
+		s += g[i]
+	}
+	for i := range g {
+		u := float64(i-r) / sigma
+		h0, h1 := 1.0, u
+		if order == 0 {
+			h1 = 1
+		}
+		for n := 1; n < order; n++ {
+			h0, h1 = h1, u*h1-float64(n)*h0
+		}
+		g[i] *= h1 * math.Pow(-1/sigma, float64(order)) / s
+	}
+	return g
+}
+
+// Gaussian kernel of standard deviation sigma, of sum 1,
+// truncated at 3 sigma into a square of odd side
+func GaussianKernel(sigma float64) General[float64] {
+	g := gaussian1D(sigma, 0, kernelRadius(3*sigma))
+	return outerKernel(g, g)
+}
+
+// Derivative-of-Gaussian kernel of standard deviation sigma, of orders ox along x
+// and oy along y, truncated farther for higher orders, whose lobes are wider.
+// Correlation by it, as by Conv, gives the derivative of the smoothed matrix
+// up to the sign (-1)^(ox+oy).
+func GaussianDerivativeKernel(sigma float64, ox, oy int) General[float64] {
+	rx, ry := kernelRadius((3+0.5*float64(ox))*sigma), kernelRadius((3+0.5*float64(oy))*sigma)
+	r := max(rx, ry)
+	return outerKernel(gaussian1D(sigma, max(oy, 0), r), gaussian1D(sigma, max(ox, 0), r))
+}
+
+// Box kernel of x*y elements, of sum 1
+func BoxKernel(x, y int) General[float64] {
+	m := NewGeneral[float64](x, y)
+	for i := range m.val {
+		m.val[i] = 1 / float64(len(m.val))
+	}
+	return m
+}
+
+// Disk kernel of the given radius, of sum 1,
+// of which the elements on the edge are weighted by their area inside
+func DiskKernel(radius float64) General[float64] {
+	const sub = 8 // subsamples per element side
+	r := kernelRadius(radius - 0.5)
+	m := NewGeneral[float64](2*r+1, 2*r+1)
+	for i := range m.val {
+		x, y := float64(i%m.x-r), float64(i/m.x-r)
+		for j := range sub * sub {
+			dx, dy := (float64(j%sub)+0.5)/sub-0.5, (float64(j/sub)+0.5)/sub-0.5
+			if math.Hypot(x+dx, y+dy) <= radius {
+				m.val[i]++
+			}
+		}
+	}
+	if !(radius > 0) {
+		m.val[0] = 1
+	}
+	return normalizePSF(m)
+}
+
+// Difference-of-Gaussians kernel, the Gaussian of sigma1 minus that of sigma2,
+// of sum 0, a band-pass approximating the Laplacian of Gaussian for sigma2 ≈ 1.6*sigma1
+func DoGKernel(sigma1, sigma2 float64) General[float64] {
+	r := kernelRadius(3 * max(sigma1, sigma2))
+	g1, g2 := gaussian1D(sigma1, 0, r), gaussian1D(sigma2, 0, r)
+	m, m2 := outerKernel(g1, g1), outerKernel(g2, g2)
+	for i, t := range m2.val {
+		m.val[i] -= t
+	}
+	return m
+}
+
+// Gabor kernel, the Gaussian of standard deviation sigma along the orientation theta
+// and sigma/gamma across, modulating a sinusoid of frequency freq in cycles per element
+// along theta with phase psi, as
+//
+//	exp(-(u² + gamma²v²)/2sigma²)·cos(2π·freq·u + psi)
+//
+// of u = x·cos(theta) + y·sin(theta), v = -x·sin(theta) + y·cos(theta),
+// truncated at 3 sigma along the longer axis
+func GaborKernel(sigma, theta, freq, psi, gamma float64) General[float64] {
+	r := kernelRadius(3 * sigma / min(gamma, 1))
+	s, c := math.Sincos(theta)
+	m := NewGeneral[float64](2*r+1, 2*r+1)
+	for i := range m.val {
+		x, y := float64(i%m.x-r), float64(i/m.x-r)
+		u, v := x*c+y*s, -x*s+y*c
+		m.val[i] = math.Exp(-(u*u+gamma*gamma*v*v)/(2*sigma*sigma)) * math.Cos(2*math.Pi*freq*u+psi)
+	}
+	return m
+}
+
+// Round a kernel scaled by scale into integers, of the sum exactly the rounded sum
+// of the scaled kernel, as 0 of the Laplace kernels. The rounding errors are
+// distributed by the largest remainder method, the elements of equal remainders
+// rounded alike to keep the symmetries of the kernel, and the rest of the sum
+// that would split such elements added to the center.
+func RoundKernel(k Matrix[float64], scale float64) General[int] {
+	const eps = 1e-9 // of the remainders taken as equal
+	v := ViewOf(k)
+	r, f := NewGeneral[int](v.x, v.y), make([]float64, v.x*v.y)
+	s, n := 0.0, 0
+	for p, t := range v.Range(1, 1) {
+		i, u := p[1]*v.x+p[0], t*scale
+		r.val[i] = int(math.Floor(u))
+		f[i] = u - math.Floor(u)
+		s += u
+		n += r.val[i]
+	}
+	order := make([]int, len(f))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		switch {
+		case f[i] > f[j]:
+			return -1
+		case f[i] < f[j]:
+			return 1
+		}
+		return 0
+	})
+	rest := max(int(math.Round(s))-n, 0)
+	for len(order) > 0 && rest > 0 {
+		g := 1
+		for g < len(order) && f[order[0]]-f[order[g]] <= eps {
+			g++
+		}
+		if g > rest {
+			break
+		}
+		for _, i := range order[:g] {
+			r.val[i]++
+		}
+		order, rest = order[g:], rest-g
+	}
+	if len(r.val) > 0 {
+		r.val[v.y/2*v.x+v.x/2] += rest
+	}
+	return r
+}
+
+// Round a kernel into integers of the largest magnitude maxAbs, of the exact sum
+// as by [RoundKernel]
+func IntegerKernel(k Matrix[float64], maxAbs int) General[int] {
+	m := 0.0
+	for _, t := range ViewOf(k).Range(1, 1) {
+		m = max(m, math.Abs(t))
+	}
+	if m == 0 {
+		d := k.Dims()
+		return NewGeneral[int](d[0], d[1])
+	}
+	return RoundKernel(k, float64(maxAbs)/m)
+}
@@ -0,0 +1,233 @@
+package matrix
+
+import (
+	"image"
+	types "imagetools/types"
+	"math"
+)
+
+// Structuring element of morphology, the nonzero elements of a mask
+// centered at (x/2,y/2) of the mask
+type StructElement struct {
+	mask General[uint8]
+}
+
+// Square structuring element of the given side
+func SquareElement(size int) StructElement {
+	return RectElement(size, size)
+}
+
+// Rectangular structuring element of x*y elements
+func RectElement(x, y int) StructElement {
+	return StructElement{UniformMatrix[uint8](max(x, 0), max(y, 0), 1)}
+}
+
+// Disk structuring element of the elements within radius of the center
+func DiskElement(radius float64) StructElement {
+	r := kernelRadius(math.Floor(radius))
+	m := NewGeneral[uint8](2*r+1, 2*r+1)
+	for i := range m.val {
+		if math.Hypot(float64(i%m.x-r), float64(i/m.x-r)) <= radius {
+			m.val[i] = 1
+		}
+	}
+	m.val[r*m.x+r] = 1
+	return StructElement{m}
+}
+
+// Cross structuring element of the middle row and column of a square of the given side
+func CrossElement(size int) StructElement {
+	m := NewGeneral[uint8](max(size, 0), max(size, 0))
+	for i := range m.val {
+		if i%m.x == m.x/2 || i/m.x == m.y/2 {
+			m.val[i] = 1
+		}
+	}
+	return StructElement{m}
+}
+
+// Structuring element of the nonzero elements of m
+func MaskElement(m Matrix[uint8]) StructElement {
+	return StructElement{ViewOf(m).Clone()}
+}
+
+// Mask of the structuring element
+func (e StructElement) Mask() General[uint8] {
+	return e.mask.Clone()
+}
+
+// Horizontal run of a structuring element, of the offsets s to s+l-1 from the center
+// along x in the row of offset dy
+type morphRun struct{ dy, s, l int }
+
+// Runs of the structuring element, or of its reflection about the center
+func (e StructElement) runs(reflect bool) []morphRun {
+	var rs []morphRun
+	cx, cy := e.mask.x/2, e.mask.y/2
+	for j := range e.mask.y {
+		for i := 0; i < e.mask.x; i++ {
+			if e.mask.val[j*e.mask.x+i] == 0 {
+				continue
+			}
+			l := 1
+			for i+l < e.mask.x && e.mask.val[j*e.mask.x+i+l] != 0 {
+				l++
+			}
+			r := morphRun{j - cy, i - cx, l}
+			if reflect {
+				r.dy, r.s = -r.dy, -r.s-l+1
+			}
+			rs = append(rs, r)
+			i += l
+		}
+	}
+	return rs
+}
+
+// Operation of morphology
+type MorphOp int
+
+const (
+	MorphErode    MorphOp = iota // minimum over the element
+	MorphDilate                  // maximum over the reflected element
+	MorphOpen                    // dilation of the erosion, removing the bright details smaller than the element
+	MorphClose                   // erosion of the dilation, filling the dark details smaller than the element
+	MorphTopHat                  // the matrix minus its opening, the bright details
+	MorphBlackHat                // the closing minus the matrix, the dark details
+	MorphGradient                // the dilation minus the erosion, the edges
+)
+
+// Grayscale morphology of a matrix by the structuring element.
+// The elements beyond the borders are ignored.
+//
+// Rectangular elements are decomposed into a row and a column, and others into
+// horizontal runs, of which the minima and maxima are computed
+// by the van Herk/Gil-Werman algorithm in O(1) per element regardless of their length.
+// Binary matrices, as thresholded edge maps, are the special case of 2 values.
+func Morph[T types.Real](m Matrix[T], op MorphOp, e StructElement) (General[T], error) {
+	m1 := ViewOf(m).Clone()
+	if m1.Empty() {
+		return General[T]{}, ErrEmptyMatrix
+	}
+	erode, dilate := e.runs(false), e.runs(true)
+	if len(erode) == 0 {
+		return General[T]{}, ErrEmptyMatrix
+	}
+	lo, hi := Min(m1), Max(m1)
+	minT := func(a, b T) T { return min(a, b) }
+	maxT := func(a, b T) T { return max(a, b) }
+	// The extremes of m are the identities of min and max among its elements
+	er := func(m General[T]) General[T] { return morphRuns(m, e.mask.y, erode, hi, minT) }
+	di := func(m General[T]) General[T] { return morphRuns(m, e.mask.y, dilate, lo, maxT) }
+	diff := func(a, b General[T]) General[T] {
+		for i, t := range b.val {
+			a.val[i] -= t
+		}
+		return a
+	}
+	switch op {
+	case MorphErode:
+		return er(m1), nil
+	case MorphDilate:
+		return di(m1), nil
+	case MorphOpen:
+		return di(er(m1)), nil
+	case MorphClose:
+		return er(di(m1)), nil
+	case MorphTopHat:
+		return diff(m1, di(er(m1))), nil
+	case MorphBlackHat:
+		return diff(er(di(m1)), m1), nil
+	case MorphGradient:
+		return diff(di(m1), er(m1)), nil
+	}
+	return General[T]{}, ErrOutOfBounds
+}
+
+// Grayscale morphology of an image by the structuring element, as by [Morph]
+func MorphGray(m *image.Gray, op MorphOp, e StructElement) (*image.Gray, error) {
+	r, err := Morph[uint8](Gray2Matrix(m), op, e)
+	if err != nil {
+		return nil, err
+	}
+	return Matrix2Gray(r), nil
+}
+
+// Combine by op the elements of m over the runs of an element of the given height,
+// id being the identity of op among the elements of m
+func morphRuns[T types.Real](m General[T], height int, rs []morphRun, id T, op func(a, b T) T) General[T] {
+	r := NewGeneral[T](m.x, m.y)
+	// Rectangles, of a run of the same offsets in each of the rows, are separable
+	rect, l, dy := len(rs) == height, height, rs[0].dy
+	for _, u := range rs {
+		rect = rect && u.s == rs[0].s && u.l == rs[0].l
+		l, dy = max(l, u.l), min(dy, u.dy)
+	}
+	buf := make([]T, 3*(max(m.x, m.y)+l))
+	if rect {
+		h := NewGeneral[T](m.x, m.y)
+		for y := range m.y {
+			morphLine(h.val[y*m.x:], m.val[y*m.x:], m.x, 1, rs[0].s, rs[0].l, id, op, buf)
+		}
+		for x := range m.x {
+			morphLine(r.val[x:], h.val[x:], m.y, m.x, dy, height, id, op, buf)
+		}
+		return r
+	}
+
+	for i := range r.val {
+		r.val[i] = id
+	}
+	rows := map[[2]int]General[T]{}
+	for _, u := range rs {
+		h, ok := rows[[2]int{u.s, u.l}]
+		if !ok {
+			h = NewGeneral[T](m.x, m.y)
+			for y := range m.y {
+				morphLine(h.val[y*m.x:], m.val[y*m.x:], m.x, 1, u.s, u.l, id, op, buf)
+			}
+			rows[[2]int{u.s, u.l}] = h
+		}
+		for y := max(-u.dy, 0); y < min(m.y-u.dy, m.y); y++ {
+			d, s := r.val[y*m.x:(y+1)*m.x], h.val[(y+u.dy)*m.x:]
+			for x, t := range d {
+				d[x] = op(t, s[x])
+			}
+		}
+	}
+	return r
+}
+
+// Combine by op the windows of length l over the n elements of src of the given stride,
+// into dst[i] = op(src[i+s], ..., src[i+s+l-1]) of the same stride,
+// of the elements beyond [0,n) being id. The windows are combined by the van Herk/Gil-Werman
+// algorithm: within blocks of l, the prefixes g and the suffixes h are accumulated,
+// and each window, straddling 2 blocks, is op(h[i], g[i+l-1]).
+func morphLine[T types.Real](dst, src []T, n, stride, s, l int, id T, op func(a, b T) T, buf []T) {
+	k := n + l - 1
+	p, g, h := buf[:k], buf[k:2*k], buf[2*k:3*k]
+	for i := range p {
+		if j := i + s; j >= 0 && j < n {
+			p[i] = src[j*stride]
+		} else {
+			p[i] = id
+		}
+	}
+	for i, t := range p {
+		if i%l == 0 {
+			g[i] = t
+		} else {
+			g[i] = op(g[i-1], t)
+		}
+	}
+	for i := k - 1; i >= 0; i-- {
+		if i%l == l-1 || i == k-1 {
+			h[i] = p[i]
+		} else {
+			h[i] = op(h[i+1], p[i])
+		}
+	}
+	for i := range n {
+		dst[i*stride] = op(h[i], g[i+l-1])
+	}
+}
@@ -0,0 +1,217 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"runtime"
+	"sync"
+)
+
+const (
+	mulBlock    = 128     // edge length of the cache blocks, in elements
+	mulParallel = 1 << 16 // minimum multiply-adds per goroutine
+)
+
+// General multiplication a*b
+func MulMat[T types.Number](a, b Matrix[T]) (*General[T], error) {
+	return MulMatTrans(a, b, false, false)
+}
+
+// General multiplication op(a)*op(b), where op(m) is the transverse of m if the
+// corresponding flag is set, or m itself otherwise. Neither the transverses nor
+// the views of a and b are materialized.
+func MulMatTrans[T types.Number](ma, mb Matrix[T], ta, tb bool) (*General[T], error) {
+	a, b := ViewOf(ma), ViewOf(mb)
+	ay, ax := a.y, a.x // rows and columns of op(a)
+	if ta {
+		ay, ax = ax, ay
+	}
+	by, bx := b.y, b.x // rows and columns of op(b)
+	if tb {
+		by, bx = bx, by
+	}
+	if ax != by {
+		return nil, &DimensionError{
+			Op:   "MulMatTrans",
+			Dims: []Index2{{ax, ay}, {bx, by}},
+			Why:  ErrDimensions,
+		}
+	}
+	r := NewGeneral[T](bx, ay)
+	if r.Empty() || ax <= 0 {
+		return &r, nil
+	}
+	mm := mulMat[T]{r: r, a: a, b: b, ta: ta, tb: tb, n: ax}
+	mm.axpy, mm.dot = mulKernels[T]()
+	workers := min(runtime.GOMAXPROCS(0), ay, max(1, ay*ax*bx/mulParallel))
+	if workers <= 1 {
+		mm.rows(0, ay)
+		return &r, nil
+	}
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func(y0, y1 int) {
+			defer wg.Done()
+			mm.rows(y0, y1)
+		}(ay*w/workers, ay*(w+1)/workers)
+	}
+	wg.Wait()
+	return &r, nil
+}
+
+// State of a multiplication r = op(a)*op(b)
+type mulMat[T types.Number] struct {
+	r      General[T]
+	a, b   View[T]
+	ta, tb bool
+	n      int // inner dimension
+	axpy   func(y, x []T, t T)
+	dot    func(x, y []T) T
+}
+
+// Compute rows [y0,y1) of the product, block by block
+func (mm *mulMat[T]) rows(y0, y1 int) {
+	r, a, b := mm.r, mm.a, mm.b
+	// Buffers of the lines of a and b of which the elements are not adjacent in storage
+	bufA, bufB := make([]T, mulBlock), make([]T, mulBlock)
+	for k0 := 0; k0 < mm.n; k0 += mulBlock {
+		k1 := min(k0+mulBlock, mm.n)
+		if mm.tb { // rows of b are columns of op(b), use inner products
+			for i := y0; i < y1; i++ {
+				var ai []T
+				if mm.ta {
+					ai = a.line(i, k0, k1-k0, true, bufA)
+				} else {
+					ai = a.line(k0, i, k1-k0, false, bufA)
+				}
+				ri := r.val[i*r.x : (i+1)*r.x]
+				for j := range ri {
+					ri[j] += mm.dot(ai, b.line(k0, j, k1-k0, false, bufB))
+				}
+			}
+			continue
+		}
+		for x0 := 0; x0 < r.x; x0 += mulBlock {
+			x1 := min(x0+mulBlock, r.x)
+			for i := y0; i < y1; i++ {
+				ri := r.val[i*r.x+x0 : i*r.x+x1]
+				for k := k0; k < k1; k++ {
+					var aik T
+					if mm.ta {
+						aik = a.val[a.off+i*a.sx+k*a.sy]
+					} else {
+						aik = a.val[a.off+k*a.sx+i*a.sy]
+					}
+					if aik != 0 {
+						mm.axpy(ri, b.line(x0, k, x1-x0, false, bufB), aik)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Select the inner kernels, specialized for the common floating-point types
+func mulKernels[T types.Number]() (axpy func(y, x []T, t T), dot func(x, y []T) T) {
+	axpy, dot = axpyGeneric[T], dotGeneric[T]
+	if f, ok := any(axpyFloat64).(func([]T, []T, T)); ok {
+		axpy, dot = f, any(dotFloat64).(func([]T, []T) T)
+	} else if f, ok := any(axpyFloat32).(func([]T, []T, T)); ok {
+		axpy, dot = f, any(dotFloat32).(func([]T, []T) T)
+	} else if f, ok := any(axpyComplex128).(func([]T, []T, T)); ok {
+		axpy, dot = f, any(dotComplex128).(func([]T, []T) T)
+	}
+	return axpy, dot
+}
+
+// y += t*x
+func axpyGeneric[T types.Number](y, x []T, t T) {
+	for i, v := range x {
+		y[i] += t * v
+	}
+}
+
+// Inner product of x and y
+func dotGeneric[T types.Number](x, y []T) (s T) {
+	for i, v := range x {
+		s += v * y[i]
+	}
+	return s
+}
+
+func axpyFloat64(y, x []float64, t float64) {
+	y, i := y[:len(x)], 0
+	for ; i+4 <= len(x); i += 4 {
+		y[i] += t * x[i]
+		y[i+1] += t * x[i+1]
+		y[i+2] += t * x[i+2]
+		y[i+3] += t * x[i+3]
+	}
+	for ; i < len(x); i++ {
+		y[i] += t * x[i]
+	}
+}
+
+func dotFloat64(x, y []float64) float64 {
+	y, i := y[:len(x)], 0
+	var s0, s1, s2, s3 float64
+	for ; i+4 <= len(x); i += 4 {
+		s0 += x[i] * y[i]
+		s1 += x[i+1] * y[i+1]
+		s2 += x[i+2] * y[i+2]
+		s3 += x[i+3] * y[i+3]
+	}
+	for ; i < len(x); i++ {
+		s0 += x[i] * y[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+func axpyFloat32(y, x []float32, t float32) {
+	y, i := y[:len(x)], 0
+	for ; i+4 <= len(x); i += 4 {
+		y[i] += t * x[i]
+		y[i+1] += t * x[i+1]
+		y[i+2] += t * x[i+2]
+		y[i+3] += t * x[i+3]
+	}
+	for ; i < len(x); i++ {
+		y[i] += t * x[i]
+	}
+}
+
+func dotFloat32(x, y []float32) float32 {
+	y, i := y[:len(x)], 0
+	var s0, s1, s2, s3 float32
+	for ; i+4 <= len(x); i += 4 {
+		s0 += x[i] * y[i]
+		s1 += x[i+1] * y[i+1]
+		s2 += x[i+2] * y[i+2]
+		s3 += x[i+3] * y[i+3]
+	}
+	for ; i < len(x); i++ {
+		s0 += x[i] * y[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// Complex kernels accumulate real and imaginary parts separately.
+func axpyComplex128(y, x []complex128, t complex128) {
+	y = y[:len(x)]
+	tr, ti := real(t), imag(t)
+	for i, v := range x {
+		vr, vi := real(v), imag(v)
+		y[i] += complex(tr*vr-ti*vi, tr*vi+ti*vr)
+	}
+}
+
+func dotComplex128(x, y []complex128) complex128 {
+	y = y[:len(x)]
+	var sr, si float64
+	for i, v := range x {
+		vr, vi, wr, wi := real(v), imag(v), real(y[i]), imag(y[i])
+		sr += vr*wr - vi*wi
+		si += vr*wi + vi*wr
+	}
+	return complex(sr, si)
+}
@@ -0,0 +1,146 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// Reference product op(a)*op(b) by the definition
+func mulNaive[T types.Number](a, b General[T], ta, tb bool) General[T] {
+	opa := func(i, k int) T {
+		if ta {
+			return a.val[k*a.x+i]
+		}
+		return a.val[i*a.x+k]
+	}
+	opb := func(k, j int) T {
+		if tb {
+			return b.val[j*b.x+k]
+		}
+		return b.val[k*b.x+j]
+	}
+	ay, n, bx := a.y, a.x, b.x
+	if ta {
+		ay, n = a.x, a.y
+	}
+	if tb {
+		bx = b.y
+	}
+	r := NewGeneral[T](bx, ay)
+	for i := range ay {
+		for j := range bx {
+			var s T
+			for k := range n {
+				s += opa(i, k) * opb(k, j)
+			}
+			r.val[i*bx+j] = s
+		}
+	}
+	return r
+}
+
+// Check MulMatTrans of op(a) of y*n and op(b) of n*x against mulNaive, for each
+// combination of transposition, also of a as a window of a larger matrix,
+// of the elements within tol*n by the distance d
+func testMulMat[T types.Number](t *testing.T, gen func() T, d func(s, t T) float64, tol float64, x, y, n int) {
+	t.Helper()
+	rnd := func(x, y int) General[T] {
+		m := NewGeneral[T](x, y)
+		for i := range m.val {
+			m.val[i] = gen()
+		}
+		return m
+	}
+	for _, ta := range []bool{false, true} {
+		for _, tb := range []bool{false, true} {
+			a, b := rnd(n, y), rnd(x, n)
+			if ta {
+				a = rnd(y, n)
+			}
+			if tb {
+				b = rnd(n, x)
+			}
+			want := mulNaive(a, b, ta, tb)
+			big := rnd(a.x+3, a.y+2)
+			for p, v := range a.Range(1, 1) {
+				big.val[(p[1]+1)*big.x+p[0]+2] = v
+			}
+			window, _ := big.View().SubMatrix(2, 1, a.x+2, a.y+1)
+			for _, op := range []Matrix[T]{a, window} {
+				got, err := MulMatTrans(op, b, ta, tb)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Dims() != want.Dims() {
+					t.Fatalf("ta=%v tb=%v: dims %v, want %v", ta, tb, got.Dims(), want.Dims())
+				}
+				for i, v := range want.val {
+					if e := d(got.val[i], v); e > tol*float64(n) {
+						t.Fatalf("ta=%v tb=%v (%d*%d*%d): element %d off by %g", ta, tb, y, n, x, i, e)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMulMat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	f64 := func() float64 { return r.Float64()*2 - 1 }
+	f32 := func() float32 { return float32(f64()) }
+	c128 := func() complex128 { return complex(f64(), f64()) }
+	i := func() int { return r.Intn(21) - 10 }
+	// Non-square, and beyond mulBlock and mulParallel
+	for _, s := range [][3]int{{1, 1, 1}, {7, 3, 5}, {2, 9, 4}, {150, 140, 130}} {
+		testMulMat(t, f64, func(s, t float64) float64 { return math.Abs(s - t) }, 1e-14, s[0], s[1], s[2])
+		testMulMat(t, f32, func(s, t float32) float64 { return math.Abs(float64(s - t)) }, 1e-6, s[0], s[1], s[2])
+		testMulMat(t, c128, func(s, t complex128) float64 { return cmplx.Abs(s - t) }, 1e-14, s[0], s[1], s[2])
+		testMulMat(t, i, func(s, t int) float64 { return math.Abs(float64(s - t)) }, 0, s[0], s[1], s[2])
+	}
+	if _, err := MulMat(NewGeneral[float64](3, 2), NewGeneral[float64](3, 2)); err == nil {
+		t.Fatal("inconsistent dimensions")
+	}
+	p, err := MulMat(NewGeneral(2, 2, 1, 2, 3, 4), NewGeneral(2, 1, 5, 6))
+	if err == nil {
+		t.Fatal("inconsistent dimensions", p)
+	}
+	p, err = MulMat(NewGeneral(2, 2, 1, 2, 3, 4), NewGeneral(1, 2, 5, 6))
+	if err != nil || p.val[0] != 17 || p.val[1] != 39 {
+		t.Fatal(p, err)
+	}
+}
+
+func benchmarkMulMat[T types.Number](b *testing.B, x, y General[T], ta, tb bool) {
+	for range b.N {
+		MulMatTrans(x, y, ta, tb)
+	}
+}
+
+func BenchmarkMulMatFloat64_64(b *testing.B) {
+	benchmarkMulMat(b, RandFloatMatrix(64, 64), RandFloatMatrix(64, 64), false, false)
+}
+func BenchmarkMulMatFloat64_512(b *testing.B) {
+	benchmarkMulMat(b, RandFloatMatrix(512, 512), RandFloatMatrix(512, 512), false, false)
+}
+func BenchmarkMulMatFloat32_512(b *testing.B) {
+	benchmarkMulMat(b, ConvertMatrix[float32](RandFloatMatrix(512, 512)),
+		ConvertMatrix[float32](RandFloatMatrix(512, 512)), false, false)
+}
+func BenchmarkMulMatComplex128_256(b *testing.B) {
+	benchmarkMulMat(b, MakeComplex(RandFloatMatrix(256, 256)), MakeImag(RandFloatMatrix(256, 256)), false, false)
+}
+func BenchmarkMulMatInt_512(b *testing.B) {
+	benchmarkMulMat(b, RandIntMatrix(512, 512, 256), RandIntMatrix(512, 512, 256), false, false)
+}
+func BenchmarkMulMatTransA_512(b *testing.B) {
+	benchmarkMulMat(b, RandFloatMatrix(512, 512), RandFloatMatrix(512, 512), true, false)
+}
+func BenchmarkMulMatTransB_512(b *testing.B) {
+	benchmarkMulMat(b, RandFloatMatrix(512, 512), RandFloatMatrix(512, 512), false, true)
+}
+func BenchmarkMulMatTransAB_512(b *testing.B) {
+	benchmarkMulMat(b, RandFloatMatrix(512, 512), RandFloatMatrix(512, 512), true, true)
+}
@@ -0,0 +1,227 @@
+package matrix
+
+import (
+	"image"
+	types "imagetools/types"
+)
+
+// Gaussian and Laplacian pyramids of Burt and Adelson. Each level is low-passed
+// by the binomial kernel [1 4 6 4 1]/16 along x and y before taking every other element,
+// unlike [General.Step], so that it does not alias.
+
+// Halve the rows of m into (x+1)/2 elements, low-passed, of the borders reflected as PadReflect101
+func pyrDownRows(m General[float64]) General[float64] {
+	b := Border[float64]{Mode: PadReflect101}
+	r := NewGeneral[float64]((m.x+1)/2, m.y)
+	for j := range m.y {
+		row := m.val[j*m.x : (j+1)*m.x]
+		for i := range r.x {
+			s := 0.0
+			for k, w := range [5]float64{1, 4, 6, 4, 1} {
+				s += w * row[b.source(2*i+k-2, m.x)]
+			}
+			r.val[j*r.x+i] = s / 16
+		}
+	}
+	return r
+}
+
+// Double the rows of m into x elements, interpolated by the binomial kernel,
+// the even elements being (1 6 1)/8 of the nearest ones and the odd ones (4 4)/8
+func pyrUpRows(m General[float64], x int) General[float64] {
+	b := Border[float64]{Mode: PadReflect101}
+	r := NewGeneral[float64](x, m.y)
+	for j := range m.y {
+		row := m.val[j*m.x : (j+1)*m.x]
+		at := func(i int) float64 { return row[b.source(i, m.x)] }
+		for i := range x {
+			if k := i / 2; i%2 == 0 {
+				r.val[j*x+i] = (at(k-1) + 6*at(k) + at(k+1)) / 8
+			} else {
+				r.val[j*x+i] = (at(k) + at(k+1)) / 2
+			}
+		}
+	}
+	return r
+}
+
+// Low-pass and halve a matrix into ((x+1)/2, (y+1)/2) elements
+func PyrDown[T types.Real](m Matrix[T]) General[float64] {
+	return pyrDownRows(pyrDownRows(float64Matrix(m)).Trans()).Trans()
+}
+
+// Double a matrix into x*y elements, of x and y at most twice its dimensions,
+// interpolating by the kernel of [PyrDown]
+func PyrUp[T types.Real](m Matrix[T], x, y int) General[float64] {
+	return pyrUpRows(pyrUpRows(float64Matrix(m), x).Trans(), y).Trans()
+}
+
+// Maximum number of levels of the pyramid of a matrix of x*y elements,
+// the coarsest level being of at least 2 elements along the shorter side
+func PyramidMaxLevel(x, y int) int {
+	l := 0
+	for n := min(x, y); n >= 3; n = (n + 1) / 2 {
+		l++
+	}
+	return l
+}
+
+// Gaussian pyramid of a matrix, of the matrix itself followed by levels of it
+// halved by [PyrDown], limited by [PyramidMaxLevel], and non-positive for the limit
+func GaussianPyramid[T types.Real](m Matrix[T], levels int) ([]General[float64], error) {
+	g := float64Matrix(m)
+	if g.Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	if n := PyramidMaxLevel(g.x, g.y); levels <= 0 || levels > n {
+		levels = n
+	}
+	p := []General[float64]{g}
+	for range levels {
+		g = PyrDown(g)
+		p = append(p, g)
+	}
+	return p, nil
+}
+
+// Laplacian pyramid, of the band-pass levels from the finest,
+// each a level of the Gaussian pyramid minus the next one doubled by [PyrUp],
+// and the coarsest level of the Gaussian pyramid as the residual
+type LaplacianPyramid struct {
+	Levels   []General[float64]
+	Residual General[float64]
+}
+
+// Laplacian pyramid of a matrix of the given number of levels as [GaussianPyramid]
+func NewLaplacianPyramid[T types.Real](m Matrix[T], levels int) (LaplacianPyramid, error) {
+	g, err := GaussianPyramid(m, levels)
+	if err != nil {
+		return LaplacianPyramid{}, err
+	}
+	p := LaplacianPyramid{Residual: g[len(g)-1]}
+	for i, l := range g[:len(g)-1] {
+		u := PyrUp(g[i+1], l.x, l.y)
+		for k, t := range u.val {
+			l.val[k] -= t
+		}
+		p.Levels = append(p.Levels, l)
+	}
+	return p, nil
+}
+
+// Reconstruct the matrix from its Laplacian pyramid, exactly up to rounding
+func (p LaplacianPyramid) Reconstruct() General[float64] {
+	r := p.Residual.Clone()
+	for i := len(p.Levels) - 1; i >= 0; i-- {
+		l := p.Levels[i]
+		u := PyrUp(r, l.x, l.y)
+		for k, t := range l.val {
+			u.val[k] += t
+		}
+		r = u
+	}
+	return r
+}
+
+// Multi-band blending of the Laplacian pyramids p and q of the same dimensions,
+// each level weighted by the level of the Gaussian pyramid of mask, the weights of p in [0,1],
+// of the dimensions of the finest level. The seams are thus blended over scales
+// proportional to the wavelengths of the levels.
+func (p LaplacianPyramid) Blend(q LaplacianPyramid, mask Matrix[float64]) (LaplacianPyramid, error) {
+	if len(q.Levels) != len(p.Levels) {
+		return LaplacianPyramid{}, &DimensionError{
+			Op:   "Blend",
+			Dims: []Index2{{len(p.Levels), 1}, {len(q.Levels), 1}},
+			Why:  ErrDimensions,
+		}
+	}
+	w := []General[float64]{float64Matrix(mask)}
+	for len(w) <= len(p.Levels) {
+		w = append(w, PyrDown(w[len(w)-1]))
+	}
+	blend := func(a, b, w General[float64]) (General[float64], error) {
+		if a.Dims() != b.Dims() || a.Dims() != w.Dims() {
+			return General[float64]{}, &DimensionError{
+				Op:   "Blend",
+				Dims: []Index2{a.Dims(), b.Dims(), w.Dims()},
+				Why:  ErrDimensions,
+			}
+		}
+		r := NewGeneral[float64](a.x, a.y)
+		for i, t := range w.val {
+			r.val[i] = t*a.val[i] + (1-t)*b.val[i]
+		}
+		return r, nil
+	}
+	var r LaplacianPyramid
+	for i := range p.Levels {
+		l, err := blend(p.Levels[i], q.Levels[i], w[i])
+		if err != nil {
+			return LaplacianPyramid{}, err
+		}
+		r.Levels = append(r.Levels, l)
+	}
+	var err error
+	if r.Residual, err = blend(p.Residual, q.Residual, w[len(w)-1]); err != nil {
+		return LaplacianPyramid{}, err
+	}
+	return r, nil
+}
+
+// Gaussian pyramid of an image as [GaussianPyramid], of the channels premultiplied
+// by the alpha, the levels of the origin at (0,0)
+func GaussianPyramidImage(m image.Image, levels int) ([]*image.RGBA64, error) {
+	if m == nil || m.Bounds().Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	var ps [4][]General[float64]
+	for k, c := range imageChannels(m) {
+		ps[k], _ = GaussianPyramid(c, levels)
+	}
+	r := make([]*image.RGBA64, len(ps[0]))
+	for i := range r {
+		cs := []General[float64]{ps[0][i], ps[1][i], ps[2][i], ps[3][i]}
+		r[i] = channelsImage(cs, image.Rect(0, 0, cs[0].x, cs[0].y))
+	}
+	return r, nil
+}
+
+// Laplacian pyramids of the channels of an image, premultiplied by the alpha
+type ImagePyramid struct {
+	Channels [4]LaplacianPyramid // R, G, B and A
+	Rect     image.Rectangle     // bounds of the image
+}
+
+// Laplacian pyramids of an image of the given number of levels as [GaussianPyramid]
+func NewImagePyramid(m image.Image, levels int) (ImagePyramid, error) {
+	if m == nil || m.Bounds().Empty() {
+		return ImagePyramid{}, ErrEmptyMatrix
+	}
+	p := ImagePyramid{Rect: m.Bounds()}
+	for k, c := range imageChannels(m) {
+		p.Channels[k], _ = NewLaplacianPyramid(c, levels)
+	}
+	return p, nil
+}
+
+// Reconstruct the image from its pyramids
+func (p ImagePyramid) Reconstruct() *image.RGBA64 {
+	cs := make([]General[float64], len(p.Channels))
+	for k, c := range p.Channels {
+		cs[k] = c.Reconstruct()
+	}
+	return channelsImage(cs, p.Rect)
+}
+
+// Multi-band blending of the pyramids of 2 images as [LaplacianPyramid.Blend],
+// of the bounds of p
+func (p ImagePyramid) Blend(q ImagePyramid, mask Matrix[float64]) (ImagePyramid, error) {
+	r := ImagePyramid{Rect: p.Rect}
+	for k := range p.Channels {
+		var err error
+		if r.Channels[k], err = p.Channels[k].Blend(q.Channels[k], mask); err != nil {
+			return ImagePyramid{}, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,484 @@
+package matrix
+
+import (
+	"image"
+	types "imagetools/types"
+	"slices"
+)
+
+// N-dimensional strided tensor, in row-major order (the last axis varies fastest).
+//
+// Element idx of the tensor is val[off+sum(idx[i]*stride[i])].
+// Like [View], tensors returned by Reshape, Permute, BroadcastTo and Narrow
+// share storage with their origin wherever possible.
+type Tensor[T types.Number] struct {
+	val    []T
+	off    int
+	shape  []int
+	stride []int
+}
+
+// Create a new tensor of the given shape, zero tensor by default
+func NewTensor[T types.Number](shape []int, t ...T) Tensor[T] {
+	n := 1
+	for _, v := range shape {
+		if v <= 0 {
+			return Tensor[T]{}
+		}
+		n *= v
+	}
+	v := make([]T, n)
+	copy(v, t)
+	return Tensor[T]{val: v, shape: slices.Clone(shape), stride: rowMajor(shape)}
+}
+
+// Row-major strides of a contiguous tensor
+func rowMajor(shape []int) []int {
+	s, n := make([]int, len(shape)), 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		s[i] = n
+		n *= shape[i]
+	}
+	return s
+}
+
+// Tensor of shape [y x] sharing storage with a matrix or view
+func TensorOf[T types.Number](m Matrix[T]) Tensor[T] {
+	v := ViewOf(m)
+	if v.Empty() {
+		return Tensor[T]{}
+	}
+	return Tensor[T]{val: v.val, off: v.off, shape: []int{v.y, v.x}, stride: []int{v.sy, v.sx}}
+}
+
+// Tensor of shape [height width 4] sharing storage with the RGBA pixels of an image
+func RGBATensor(m image.Image) Tensor[uint8] {
+	m1 := RGBA(m)
+	if m1 == nil || m1.Rect.Empty() {
+		return Tensor[uint8]{}
+	}
+	return Tensor[uint8]{
+		val:    m1.Pix,
+		shape:  []int{m1.Rect.Dy(), m1.Rect.Dx(), 4},
+		stride: []int{m1.Stride, 4, 1},
+	}
+}
+
+// Stack tensors of the same shape along a new leading axis, into new storage.
+//
+// Matrices can be stacked by way of [TensorOf], for example the channels of
+// [RGBA2Matrices] into a tensor of shape [4 height width].
+func Stack[T types.Number](ts ...Tensor[T]) (Tensor[T], error) {
+	if len(ts) == 0 {
+		return Tensor[T]{}, nil
+	}
+	shape := ts[0].shape
+	for _, t := range ts[1:] {
+		if !slices.Equal(t.shape, shape) {
+			return Tensor[T]{}, &ShapeError{
+				Op:     "Stack",
+				Shapes: [][]int{shape, t.shape},
+				Why:    ErrDimensions,
+			}
+		}
+	}
+	r := NewTensor[T](append([]int{len(ts)}, shape...))
+	n := r.stride[0]
+	for i, t := range ts {
+		t.copyTo(r.val[i*n : (i+1)*n])
+	}
+	return r, nil
+}
+
+// Get the shape of the tensor
+func (t Tensor[T]) Shape() []int {
+	return slices.Clone(t.shape)
+}
+
+// Get the strides of the tensor
+func (t Tensor[T]) Strides() []int {
+	return slices.Clone(t.stride)
+}
+
+// Number of axes
+func (t Tensor[T]) Rank() int {
+	return len(t.shape)
+}
+
+// Number of elements
+func (t Tensor[T]) Size() int {
+	if len(t.shape) == 0 {
+		return 0
+	}
+	n := 1
+	for _, v := range t.shape {
+		n *= v
+	}
+	return n
+}
+
+// Check if a tensor is empty
+func (t Tensor[T]) Empty() bool {
+	return t.Size() <= 0
+}
+
+// Check if the elements are stored contiguously in row-major order
+func (t Tensor[T]) Contiguous() bool {
+	n := 1
+	for i := len(t.shape) - 1; i >= 0; i-- {
+		if t.shape[i] != 1 && t.stride[i] != n {
+			return false
+		}
+		n *= t.shape[i]
+	}
+	return true
+}
+
+func (t Tensor[T]) index(idx []int) (int, error) {
+	if len(idx) != len(t.shape) {
+		return 0, &ShapeError{Op: "Tensor.At", Shapes: [][]int{t.shape, idx}, Why: ErrDimensions}
+	}
+	k := t.off
+	for i, v := range idx {
+		if v < 0 || v >= t.shape[i] {
+			return 0, &ShapeError{Op: "Tensor.At", Shapes: [][]int{t.shape, idx}, Why: ErrOutOfBounds}
+		}
+		k += v * t.stride[i]
+	}
+	return k, nil
+}
+
+// Get the element at idx
+func (t Tensor[T]) At(idx ...int) (T, error) {
+	k, err := t.index(idx)
+	if err != nil {
+		return 0, err
+	}
+	return t.val[k], nil
+}
+
+// Assign the element at idx to v
+func (t Tensor[T]) Assign(v T, idx ...int) error {
+	k, err := t.index(idx)
+	if err == nil {
+		t.val[k] = v
+	}
+	return err
+}
+
+// Walk over shape in row-major order, calling f with the storage offsets of
+// each operand, given their strides and starting offsets.
+func walk(shape []int, strides [][]int, offs []int, f func(offs []int)) {
+	for _, v := range shape {
+		if v <= 0 {
+			return
+		}
+	}
+	idx, o := make([]int, len(shape)), slices.Clone(offs)
+	for {
+		f(o)
+		i := len(shape) - 1
+		for ; i >= 0; i-- {
+			for j := range o {
+				o[j] += strides[j][i]
+			}
+			if idx[i]++; idx[i] < shape[i] {
+				break
+			}
+			for j := range o {
+				o[j] -= strides[j][i] * shape[i]
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return
+		}
+	}
+}
+
+// Copy the elements in row-major order into s
+func (t Tensor[T]) copyTo(s []T) {
+	i := 0
+	walk(t.shape, [][]int{t.stride}, []int{t.off}, func(o []int) {
+		s[i] = t.val[o[0]]
+		i++
+	})
+}
+
+// Iterate the tensor in row-major order.
+// The index slice is reused between iterations.
+func (t Tensor[T]) Range() func(func([]int, T) bool) {
+	return func(yield func([]int, T) bool) {
+		if t.Empty() {
+			return
+		}
+		idx, k := make([]int, len(t.shape)), t.off
+		for {
+			if !yield(idx, t.val[k]) {
+				return
+			}
+			i := len(idx) - 1
+			for ; i >= 0; i-- {
+				k += t.stride[i]
+				if idx[i]++; idx[i] < t.shape[i] {
+					break
+				}
+				k -= t.stride[i] * t.shape[i]
+				idx[i] = 0
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+// Copy the tensor into new contiguous storage
+func (t Tensor[T]) Clone() Tensor[T] {
+	if t.Empty() {
+		return Tensor[T]{}
+	}
+	r := NewTensor[T](t.shape)
+	t.copyTo(r.val)
+	return r
+}
+
+// Reshape the tensor, sharing storage if it is contiguous.
+// At most one length can be -1, which is inferred from the size of the tensor.
+func (t Tensor[T]) Reshape(shape ...int) (Tensor[T], error) {
+	shape = slices.Clone(shape)
+	n, k := 1, -1
+	for i, v := range shape {
+		if v == -1 && k < 0 {
+			k = i
+		} else if v <= 0 {
+			n = -1
+			break
+		} else {
+			n *= v
+		}
+	}
+	if k >= 0 && n > 0 && t.Size()%n == 0 {
+		shape[k] = t.Size() / n
+		n = t.Size()
+	}
+	if n != t.Size() {
+		return Tensor[T]{}, &ShapeError{
+			Op:     "Reshape",
+			Shapes: [][]int{t.shape, shape},
+			Why:    ErrDimensions,
+		}
+	}
+	if !t.Contiguous() {
+		t = t.Clone()
+	}
+	return Tensor[T]{val: t.val, off: t.off, shape: shape, stride: rowMajor(shape)}, nil
+}
+
+// Permute the axes, so that axis i of the result is axis axes[i] of t, sharing storage
+func (t Tensor[T]) Permute(axes ...int) (Tensor[T], error) {
+	seen := make([]bool, len(t.shape))
+	if len(axes) != len(t.shape) {
+		return Tensor[T]{}, &ShapeError{Op: "Permute", Shapes: [][]int{t.shape, axes}, Why: ErrDimensions}
+	}
+	r := Tensor[T]{val: t.val, off: t.off, shape: make([]int, len(axes)), stride: make([]int, len(axes))}
+	for i, a := range axes {
+		if a < 0 || a >= len(axes) || seen[a] {
+			return Tensor[T]{}, &ShapeError{Op: "Permute", Shapes: [][]int{t.shape, axes}, Why: ErrOutOfBounds}
+		}
+		seen[a] = true
+		r.shape[i], r.stride[i] = t.shape[a], t.stride[a]
+	}
+	return r, nil
+}
+
+// Reverse the axes, sharing storage
+func (t Tensor[T]) Transpose() Tensor[T] {
+	t.shape, t.stride = slices.Clone(t.shape), slices.Clone(t.stride)
+	slices.Reverse(t.shape)
+	slices.Reverse(t.stride)
+	return t
+}
+
+// Restrict axis to the index range [i0,i1), sharing storage
+func (t Tensor[T]) Narrow(axis, i0, i1 int) (Tensor[T], error) {
+	if axis < 0 || axis >= len(t.shape) || i0 < 0 || i0 >= i1 || i1 > t.shape[axis] {
+		return Tensor[T]{}, &ShapeError{
+			Op:     "Narrow",
+			Shapes: [][]int{t.shape, {axis, i0, i1}},
+			Why:    ErrOutOfBounds,
+		}
+	}
+	t.shape = slices.Clone(t.shape)
+	t.off += i0 * t.stride[axis]
+	t.shape[axis] = i1 - i0
+	return t, nil
+}
+
+// Matrix view of the last two axes, with the leading axes fixed at idx, sharing storage
+func (t Tensor[T]) Plane(idx ...int) (View[T], error) {
+	n := len(t.shape)
+	if n < 2 || len(idx) != n-2 {
+		return View[T]{}, &ShapeError{Op: "Plane", Shapes: [][]int{t.shape, idx}, Why: ErrDimensions}
+	}
+	k, err := t.index(append(slices.Clone(idx), 0, 0))
+	if err != nil {
+		return View[T]{}, err
+	}
+	return View[T]{
+		val: t.val, off: k,
+		sx: t.stride[n-1], sy: t.stride[n-2],
+		x: t.shape[n-1], y: t.shape[n-2],
+	}, nil
+}
+
+// Shape that both a and b broadcast to, NumPy style:
+// shapes are aligned at the last axis, and lengths of 1 are stretched.
+func BroadcastShapes(a, b []int) ([]int, error) {
+	n := max(len(a), len(b))
+	r := make([]int, n)
+	for i := range n {
+		u, v := 1, 1
+		if j := len(a) - n + i; j >= 0 {
+			u = a[j]
+		}
+		if j := len(b) - n + i; j >= 0 {
+			v = b[j]
+		}
+		switch {
+		case u == v || v == 1:
+			r[i] = u
+		case u == 1:
+			r[i] = v
+		default:
+			return nil, &ShapeError{Op: "Broadcast", Shapes: [][]int{a, b}, Why: ErrDimensions}
+		}
+	}
+	return r, nil
+}
+
+// Broadcast the tensor to shape by zero strides, sharing storage
+func (t Tensor[T]) BroadcastTo(shape ...int) (Tensor[T], error) {
+	s, err := BroadcastShapes(t.shape, shape)
+	if err != nil || !slices.Equal(s, shape) {
+		return Tensor[T]{}, &ShapeError{Op: "BroadcastTo", Shapes: [][]int{t.shape, shape}, Why: ErrDimensions}
+	}
+	r := Tensor[T]{val: t.val, off: t.off, shape: slices.Clone(shape), stride: make([]int, len(shape))}
+	for i := range t.shape {
+		j := len(shape) - len(t.shape) + i
+		if t.shape[i] != 1 {
+			r.stride[j] = t.stride[i]
+		}
+	}
+	return r, nil
+}
+
+// Map each element of t by f into a new tensor
+func MapTensor[U, T types.Number](t Tensor[T], f func(T) U) Tensor[U] {
+	r := NewTensor[U](t.shape)
+	i := 0
+	walk(t.shape, [][]int{t.stride}, []int{t.off}, func(o []int) {
+		r.val[i] = f(t.val[o[0]])
+		i++
+	})
+	return r
+}
+
+// Combine the elements of a and b by f into a new tensor, with broadcasting
+func ZipTensor[U, T types.Number](a, b Tensor[T], f func(T, T) U) (Tensor[U], error) {
+	shape, err := BroadcastShapes(a.shape, b.shape)
+	if err != nil {
+		return Tensor[U]{}, err
+	}
+	a, _ = a.BroadcastTo(shape...)
+	b, _ = b.BroadcastTo(shape...)
+	r := NewTensor[U](shape)
+	i := 0
+	walk(shape, [][]int{a.stride, b.stride}, []int{a.off, b.off}, func(o []int) {
+		r.val[i] = f(a.val[o[0]], b.val[o[1]])
+		i++
+	})
+	return r, nil
+}
+
+// Calculate t+u elementwise, with broadcasting
+func (t Tensor[T]) Add(u Tensor[T]) (Tensor[T], error) {
+	return ZipTensor(t, u, func(a, b T) T { return a + b })
+}
+
+// Calculate t-u elementwise, with broadcasting
+func (t Tensor[T]) Sub(u Tensor[T]) (Tensor[T], error) {
+	return ZipTensor(t, u, func(a, b T) T { return a - b })
+}
+
+// Calculate t.*u elementwise, with broadcasting
+func (t Tensor[T]) Mul(u Tensor[T]) (Tensor[T], error) {
+	return ZipTensor(t, u, func(a, b T) T { return a * b })
+}
+
+// Calculate t./u elementwise, with broadcasting.
+// Integer division by zero fails with [ErrDivideBy0].
+func (t Tensor[T]) Div(u Tensor[T]) (Tensor[T], error) {
+	if isInteger[T]() {
+		for _, v := range u.Range() {
+			if v == 0 {
+				return Tensor[T]{}, ErrDivideBy0
+			}
+		}
+	}
+	return ZipTensor(t, u, func(a, b T) T { return a / b })
+}
+
+// Reduce axis of t by folding its elements with f, starting from the first one
+func reduceTensor[U, T types.Number](t Tensor[T], axis int, op string, first func(T) U, f func(U, T) U) (Tensor[U], error) {
+	if axis < 0 || axis >= len(t.shape) {
+		return Tensor[U]{}, &ShapeError{Op: op, Shapes: [][]int{t.shape, {axis}}, Why: ErrOutOfBounds}
+	} else if t.Empty() {
+		return Tensor[U]{}, ErrEmptyMatrix
+	}
+	shape := slices.Delete(slices.Clone(t.shape), axis, axis+1)
+	stride := slices.Delete(slices.Clone(t.stride), axis, axis+1)
+	n, s := t.shape[axis], t.stride[axis]
+	if len(shape) == 0 { // reduced to a scalar
+		shape, stride = []int{1}, []int{0}
+	}
+	r := NewTensor[U](shape)
+	i := 0
+	walk(shape, [][]int{stride}, []int{t.off}, func(o []int) {
+		u := first(t.val[o[0]])
+		for k := 1; k < n; k++ {
+			u = f(u, t.val[o[0]+k*s])
+		}
+		r.val[i] = u
+		i++
+	})
+	return r, nil
+}
+
+// Sum along an axis, which is removed from the shape
+func (t Tensor[T]) Sum(axis int) (Tensor[T], error) {
+	return reduceTensor(t, axis, "Sum", func(v T) T { return v }, func(u, v T) T { return u + v })
+}
+
+// Mean along an axis, which is removed from the shape
+func TensorMean[T types.Real](t Tensor[T], axis int) (Tensor[float64], error) {
+	r, err := reduceTensor(t, axis, "Mean", func(v T) float64 { return float64(v) },
+		func(u float64, v T) float64 { return u + float64(v) })
+	if err == nil {
+		n := float64(t.shape[axis])
+		for i := range r.val {
+			r.val[i] /= n
+		}
+	}
+	return r, err
+}
+
+// Maximum along an axis, which is removed from the shape
+func TensorMax[T types.Real](t Tensor[T], axis int) (Tensor[T], error) {
+	return reduceTensor(t, axis, "Max", func(v T) T { return v }, func(u, v T) T { return max(u, v) })
+}
+
+// Minimum along an axis, which is removed from the shape
+func TensorMin[T types.Real](t Tensor[T], axis int) (Tensor[T], error) {
+	return reduceTensor(t, axis, "Min", func(v T) T { return v }, func(u, v T) T { return min(u, v) })
+}
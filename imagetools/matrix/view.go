@@ -0,0 +1,266 @@
+package matrix
+
+import types "imagetools/types"
+
+// Zero-copy strided window over the storage of a matrix, like [image.RGBA.SubImage].
+//
+// Element (x,y) of the view is val[off+x*sx+y*sy]. A view shares storage with
+// the matrix it comes from, so it reflects later changes of that matrix.
+// Data are copied only by [View.Clone].
+type View[T types.Number] struct {
+	val    []T
+	off    int // index of element (0,0)
+	sx, sy int // strides between horizontally and vertically adjacent elements
+	x, y   int // row and column lengths
+}
+
+// View of the whole matrix
+func (m General[T]) View() View[T] {
+	if m.Empty() {
+		return View[T]{}
+	}
+	m.reval()
+	return View[T]{val: m.val, sx: 1, sy: m.x, x: m.x, y: m.y}
+}
+
+// View of any matrix, sharing storage with General matrices and views.
+// Other implementations of [Matrix] are read through At into new storage.
+func ViewOf[T types.Number](m Matrix[T]) View[T] {
+	switch m1 := m.(type) {
+	case nil:
+		return View[T]{}
+	case View[T]:
+		return m1
+	case *View[T]:
+		return *m1
+	case General[T]:
+		return m1.View()
+	case *General[T]:
+		return m1.View()
+	}
+	d := m.Dims()
+	r := NewGeneral[T](d[0], d[1])
+	for i := range r.val {
+		r.val[i], _ = m.At(i%r.x, i/r.x)
+	}
+	return r.View()
+}
+
+// General matrix of the same elements as m, sharing storage where possible,
+// which must be treated as read-only. Views of other layouts are copied,
+// so read-only paths walk them by [elements] or [View.line] instead.
+func dense[T types.Number](m Matrix[T]) General[T] {
+	switch m1 := m.(type) {
+	case General[T]:
+		return m1
+	case *General[T]:
+		return *m1
+	}
+	v := ViewOf(m)
+	if n := v.x * v.y; v.sx == 1 && (v.sy == v.x || v.y == 1) && n > 0 {
+		return General[T]{val: v.val[v.off : v.off+n : v.off+n], x: v.x, y: v.y}
+	}
+	return v.Clone()
+}
+
+// Iterate the elements of any matrix in row-major order with their indices in it,
+// walking the strides of General matrices and views, and reading others through At
+func elements[T types.Number](m Matrix[T]) func(func(int, T) bool) {
+	return func(yield func(int, T) bool) {
+		var v View[T]
+		switch m1 := m.(type) {
+		case nil:
+			return
+		case View[T]:
+			v = m1
+		case *View[T]:
+			v = *m1
+		case General[T]:
+			v = m1.View()
+		case *General[T]:
+			v = m1.View()
+		default:
+			d := m.Dims()
+			for i := range max(d[0], 0) * max(d[1], 0) {
+				t, _ := m.At(i%d[0], i/d[0])
+				if !yield(i, t) {
+					return
+				}
+			}
+			return
+		}
+		i := 0
+		for y := range v.y {
+			for x, j := 0, v.off+y*v.sy; x < v.x; x, j = x+1, j+v.sx {
+				if !yield(i, v.val[j]) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// n elements of the view from (x,y) along x, or along y if down, sharing storage
+// if they are adjacent in it, or copied into buf of at least n elements otherwise
+func (v View[T]) line(x, y, n int, down bool, buf []T) []T {
+	i, s := v.off+x*v.sx+y*v.sy, v.sx
+	if down {
+		s = v.sy
+	}
+	if s == 1 {
+		return v.val[i : i+n : i+n]
+	}
+	buf = buf[:n]
+	for k := range buf {
+		buf[k] = v.val[i]
+		i += s
+	}
+	return buf
+}
+
+// Get the dimension lengths of the view
+func (v View[T]) Dims() Index2 {
+	return Index2{v.x, v.y}
+}
+
+// Check if a view is empty
+func (v View[T]) Empty() bool {
+	return v.x <= 0 || v.y <= 0
+}
+
+// Get the element at (x,y)
+func (v View[T]) At(x, y int) (T, error) {
+	if x < 0 || x >= v.x || y < 0 || y >= v.y {
+		return 0, ErrOutOfBounds
+	}
+	return v.val[v.off+x*v.sx+y*v.sy], nil
+}
+
+// safe index function
+func (v View[T]) at(x, y int) T {
+	if x < 0 || x >= v.x || y < 0 || y >= v.y {
+		return 0
+	}
+	return v.val[v.off+x*v.sx+y*v.sy]
+}
+
+// Materialize the view into a new matrix
+func (v View[T]) Clone() General[T] {
+	if v.Empty() {
+		return General[T]{}
+	}
+	m := NewGeneral[T](v.x, v.y)
+	for y := range v.y {
+		r, i := m.val[y*v.x:(y+1)*v.x], v.off+y*v.sy
+		if v.sx == 1 {
+			copy(r, v.val[i:])
+			continue
+		}
+		for x := range r {
+			r[x] = v.val[i]
+			i += v.sx
+		}
+	}
+	return m
+}
+
+// Iterate the view
+func (v View[T]) Range(dx, dy int) func(func(Index2, T) bool) {
+	return func(yield func(Index2, T) bool) {
+		for y := 0; y < v.y; y += dy {
+			for x := 0; x < v.x; x += dx {
+				if !yield(Index2{x, y}, v.val[v.off+x*v.sx+y*v.sy]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sub-view of index [x0,x1) * [y0,y1)
+func (v View[T]) SubMatrix(x0, y0, x1, y1 int) (View[T], error) {
+	if x0 < 0 || x0 >= x1 || x1 > v.x || y0 < 0 || y0 >= y1 || y1 > v.y {
+		return View[T]{}, &DimensionError{
+			Dims: []Index2{{x0, y0}, {x1, y1}},
+			Op:   "SubMatrix",
+			Why:  ErrOutOfBounds,
+		}
+	}
+	v.off += x0*v.sx + y0*v.sy
+	v.x, v.y = x1-x0, y1-y0
+	return v, nil
+}
+
+// Iterate the view in the form of sub-views sized (x1,y1), without allocation
+func (v View[T]) RangeSubMatrix(dx, dy, x1, y1 int) func(func(Index2, View[T]) bool) {
+	return func(yield func(Index2, View[T]) bool) {
+		if x1 <= 0 || y1 <= 0 || dx <= 0 || dy <= 0 {
+			return
+		}
+		w := View[T]{val: v.val, sx: v.sx, sy: v.sy, x: x1, y: y1}
+		for y := 0; y <= v.y-y1; y += dy {
+			for x := 0; x <= v.x-x1; x += dx {
+				w.off = v.off + x*v.sx + y*v.sy
+				if !yield(Index2{x, y}, w) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Step the view
+func (v View[T]) Step(dx, dy int) View[T] {
+	if v.Empty() || dx <= 0 || dy <= 0 {
+		return View[T]{}
+	}
+	v.x, v.y = (v.x-1)/dx+1, (v.y-1)/dy+1
+	v.sx, v.sy = v.sx*dx, v.sy*dy
+	return v
+}
+
+// Get a particular row
+func (v View[T]) Row(y int) View[T] {
+	r, _ := v.SubMatrix(0, y, v.x, y+1)
+	return r
+}
+
+// Get a particular column
+func (v View[T]) Column(x int) View[T] {
+	c, _ := v.SubMatrix(x, 0, x+1, v.y)
+	return c
+}
+
+// Transverse of the view
+func (v View[T]) Trans() View[T] {
+	v.x, v.y, v.sx, v.sy = v.y, v.x, v.sy, v.sx
+	return v
+}
+
+// Calculate the covariance of the elements of two matrices of the same dimensions.
+func CoVMatrix[T, U types.Real](s bool, x Matrix[T], y Matrix[U]) (float64, error) {
+	vx, vy := ViewOf(x), ViewOf(y)
+	if vx.x != vy.x || vx.y != vy.y {
+		return 0, &DimensionError{
+			Op:   "CoVMatrix",
+			Dims: []Index2{vx.Dims(), vy.Dims()},
+			Why:  ErrDimensions,
+		}
+	}
+	n := float64(vx.x * vx.y)
+	if n <= 0 {
+		return 0, nil
+	}
+	sx, sy, s2 := 0.0, 0.0, 0.0
+	for p, t := range vx.Range(1, 1) {
+		u := float64(vy.at(p[0], p[1]))
+		s2 += float64(t) / n * u
+		sx += float64(t) / n
+		sy += u / n
+	}
+	if s2 -= sx * sy; s {
+		s2 /= 1 - 1/n
+	}
+	return s2, nil
+}
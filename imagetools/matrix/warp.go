@@ -0,0 +1,434 @@
+package matrix
+
+import (
+	"image"
+	"image/color"
+	types "imagetools/types"
+	"math"
+)
+
+// Interpolation of the elements between those of a matrix, in resampling
+type Interpolation int
+
+const (
+	InterpNearest  Interpolation = iota // the nearest element
+	InterpBilinear                      // linear along x and y, of 2*2 elements
+	InterpBicubic                       // Keys cubic convolution of a = -0.5, of 4*4 elements
+	InterpLanczos                       // windowed sinc of 3 lobes, of 6*6 elements
+)
+
+// Half width of the kernel of the interpolation
+func (f Interpolation) support() float64 {
+	switch f {
+	case InterpBilinear:
+		return 1
+	case InterpBicubic:
+		return 2
+	case InterpLanczos:
+		return 3
+	}
+	return 0.5
+}
+
+// Weight of the kernel of the interpolation at distance t
+func (f Interpolation) weight(t float64) float64 {
+	t = math.Abs(t)
+	switch f {
+	case InterpBilinear:
+		return max(1-t, 0)
+	case InterpBicubic:
+		const a = -0.5
+		if t < 1 {
+			return ((a+2)*t-(a+3))*t*t + 1
+		} else if t < 2 {
+			return ((a*t-5*a)*t+8*a)*t - 4*a
+		}
+		return 0
+	case InterpLanczos:
+		if t == 0 {
+			return 1
+		} else if t < 3 {
+			return 3 * math.Sin(math.Pi*t) * math.Sin(math.Pi*t/3) / (math.Pi * math.Pi * t * t)
+		}
+		return 0
+	}
+	if t < 0.5 {
+		return 1
+	}
+	return 0
+}
+
+// Sample m at (u,v) in the coordinates of its elements,
+// the elements beyond the borders being background
+func (f Interpolation) sample(m General[float64], u, v, background float64) float64 {
+	at := func(i, j int) float64 {
+		if i < 0 || i >= m.x || j < 0 || j >= m.y {
+			return background
+		}
+		return m.val[j*m.x+i]
+	}
+	if f == InterpNearest {
+		return at(int(math.Floor(u+0.5)), int(math.Floor(v+0.5)))
+	}
+	s := f.support()
+	i0, j0 := int(math.Ceil(u-s)), int(math.Ceil(v-s))
+	i1, j1 := int(math.Floor(u+s)), int(math.Floor(v+s))
+	var wx [7]float64
+	for i := i0; i <= i1; i++ {
+		wx[i-i0] = f.weight(u - float64(i))
+	}
+	r, w := 0.0, 0.0
+	for j := j0; j <= j1; j++ {
+		wy := f.weight(v - float64(j))
+		for i := i0; i <= i1; i++ {
+			r += wy * wx[i-i0] * at(i, j)
+			w += wy * wx[i-i0]
+		}
+	}
+	return r / w
+}
+
+// Resample channels of the same dimensions into x*y elements, element (i,j) of each
+// sampled at the coordinates f(i,j) of the source elements, or background where f fails
+func resample(cs []General[float64], x, y int, f func(i, j int) (u, v float64, ok bool), interp Interpolation, background []float64) []General[float64] {
+	r := make([]General[float64], len(cs))
+	for k := range r {
+		r[k] = NewGeneral[float64](x, y)
+	}
+	for n := range x * y {
+		u, v, ok := f(n%x, n/x)
+		// Only the points within the area of the source elements are sampled
+		ok = ok && u >= -0.5 && u <= float64(cs[0].x)-0.5 && v >= -0.5 && v <= float64(cs[0].y)-0.5
+		for k, c := range cs {
+			if ok {
+				r[k].val[n] = interp.sample(c, u, v, background[k])
+			} else {
+				r[k].val[n] = background[k]
+			}
+		}
+	}
+	return r
+}
+
+// Homography translating by (tx,ty)
+func Translation(tx, ty float64) General[float64] {
+	return NewGeneral(3, 3, 1, 0, tx, 0, 1, ty, 0, 0, 1)
+}
+
+// Homography scaling by sx along x and sy along y
+func Scaling(sx, sy float64) General[float64] {
+	return NewGeneral(3, 3, sx, 0, 0, 0, sy, 0, 0, 0, 1)
+}
+
+// Homography rotating by angle in radians about the origin,
+// counterclockwise as displayed, of y increasing downward
+func Rotation(angle float64) General[float64] {
+	s, c := math.Sincos(angle)
+	return NewGeneral(3, 3, c, s, 0, -s, c, 0, 0, 0, 1)
+}
+
+// Inverse of a homography, which maps the destination coordinates back
+// into the source ones, of the scale of h33 made positive so that the points
+// in front map to positive w
+func invHomography(h Matrix[float64]) (General[float64], error) {
+	if h == nil || h.Dims() != (Index2{3, 3}) {
+		var d Index2
+		if h != nil {
+			d = h.Dims()
+		}
+		return General[float64]{}, &DimensionError{
+			Op:   "Warp",
+			Dims: []Index2{d},
+			Why:  ErrDimensions,
+		}
+	}
+	var a [9]float64
+	for i, t := range elements(h) {
+		a[i] = t
+	}
+	if a[8] < 0 {
+		for i := range a {
+			a[i] = -a[i]
+		}
+	}
+	r := NewGeneral(3, 3,
+		a[4]*a[8]-a[5]*a[7], a[2]*a[7]-a[1]*a[8], a[1]*a[5]-a[2]*a[4],
+		a[5]*a[6]-a[3]*a[8], a[0]*a[8]-a[2]*a[6], a[2]*a[3]-a[0]*a[5],
+		a[3]*a[7]-a[4]*a[6], a[1]*a[6]-a[0]*a[7], a[0]*a[4]-a[1]*a[3])
+	d := a[0]*r.val[0] + a[1]*r.val[3] + a[2]*r.val[6]
+	if d == 0 || math.IsNaN(d) {
+		return General[float64]{}, ErrDivideBy0
+	}
+	r.Div(d)
+	return r, nil
+}
+
+// Map the center of the element (i,j) of the destination of offset (dx,dy)
+// into the coordinates of the elements of the source of offset (sx,sy) by the inverse homography
+func warpPoint(inv General[float64], dx, dy, sx, sy int) func(i, j int) (u, v float64, ok bool) {
+	h := inv.val
+	return func(i, j int) (u, v float64, ok bool) {
+		x, y := float64(i+dx)+0.5, float64(j+dy)+0.5
+		w := h[6]*x + h[7]*y + h[8]
+		if !(w > 0) {
+			return 0, 0, false
+		}
+		u, v = (h[0]*x+h[1]*y+h[2])/w, (h[3]*x+h[4]*y+h[5])/w
+		return u - float64(sx) - 0.5, v - float64(sy) - 0.5, true
+	}
+}
+
+// Warp a matrix by the homography h, a 3×3 matrix mapping the coordinates (x,y,1)
+// of the source into those of the destination up to scale, of the element (i,j)
+// covering [i,i+1)×[j,j+1), into a matrix of x*y elements.
+// Each element is interpolated at its center mapped back by the inverse of h,
+// the elements beyond the source being background.
+// Affine transforms are the homographies of the last row 0 0 1.
+func Warp[T types.Real](m Matrix[T], h Matrix[float64], x, y int, interp Interpolation, background T) (General[T], error) {
+	m1 := float64Matrix(m)
+	if m1.Empty() {
+		return General[T]{}, ErrEmptyMatrix
+	}
+	inv, err := invHomography(h)
+	if err != nil {
+		return General[T]{}, err
+	}
+	r := resample([]General[float64]{m1}, max(x, 0), max(y, 0), warpPoint(inv, 0, 0, 0, 0), interp, []float64{float64(background)})
+	lo, hi := integerRange[T]()
+	return MapMatrix(r[0], func(t float64) T { return saturate(t, lo, hi) }), nil
+}
+
+// Premultiplied channels of the RGBA64 pixels of an image
+func imageChannels(m image.Image) []General[float64] {
+	b := m.Bounds()
+	cs := make([]General[float64], 4)
+	for k := range cs {
+		cs[k] = NewGeneral[float64](b.Dx(), b.Dy())
+	}
+	for i := range cs[0].val {
+		c := Pixel(m, b.Min.X+i%b.Dx(), b.Min.Y+i/b.Dx())
+		cs[0].val[i], cs[1].val[i], cs[2].val[i], cs[3].val[i] = float64(c.R), float64(c.G), float64(c.B), float64(c.A)
+	}
+	return cs
+}
+
+// Image of bounds r of premultiplied channels, of the colors clamped to the alpha
+func channelsImage(cs []General[float64], r image.Rectangle) *image.RGBA64 {
+	m := image.NewRGBA64(r)
+	for n := range cs[0].val {
+		c := func(k int, a float64) uint16 { return uint16(min(max(math.Round(cs[k].val[n]), 0), a)) }
+		a := float64(c(3, 0xffff))
+		m.SetRGBA64(r.Min.X+n%r.Dx(), r.Min.Y+n/r.Dx(), color.RGBA64{c(0, a), c(1, a), c(2, a), uint16(a)})
+	}
+	return m
+}
+
+// Channels of a color, premultiplied in 16 bits
+func colorChannels(c color.Color) []float64 {
+	if c == nil {
+		return make([]float64, 4)
+	}
+	r, g, b, a := c.RGBA()
+	return []float64{float64(r), float64(g), float64(b), float64(a)}
+}
+
+// Warp an image by the homography h as [Warp], in the coordinates of the image,
+// into an image of bounds r, of the pixels beyond the source being background.
+// The channels are interpolated premultiplied by the alpha.
+func WarpImage(m image.Image, h Matrix[float64], r image.Rectangle, interp Interpolation, background color.Color) (*image.RGBA64, error) {
+	if m == nil || m.Bounds().Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	inv, err := invHomography(h)
+	if err != nil {
+		return nil, err
+	}
+	b := m.Bounds()
+	cs := resample(imageChannels(m), r.Dx(), r.Dy(), warpPoint(inv, r.Min.X, r.Min.Y, b.Min.X, b.Min.Y), interp, colorChannels(background))
+	return channelsImage(cs, r), nil
+}
+
+// Resample the rows of m of n elements into x elements, of the kernel stretched
+// by the ratio of the dimensions when shrinking against aliasing, the borders replicated
+func resizeRows(m General[float64], x int, interp Interpolation) General[float64] {
+	r := NewGeneral[float64](x, m.y)
+	scale := float64(m.x) / float64(x)
+	if interp == InterpNearest {
+		for i := range x {
+			k := min(int((float64(i)+0.5)*scale), m.x-1)
+			for j := range m.y {
+				r.val[j*x+i] = m.val[j*m.x+k]
+			}
+		}
+		return r
+	}
+	s := max(scale, 1)
+	w := make([]float64, 0, int(2*interp.support()*s)+2)
+	for i := range x {
+		c := (float64(i)+0.5)*scale - 0.5
+		i0, i1 := int(math.Ceil(c-interp.support()*s)), int(math.Floor(c+interp.support()*s))
+		w, t := w[:0], 0.0
+		for k := i0; k <= i1; k++ {
+			w = append(w, interp.weight((c-float64(k))/s))
+			t += w[k-i0]
+		}
+		for j := range m.y {
+			row, u := m.val[j*m.x:(j+1)*m.x], 0.0
+			for k := i0; k <= i1; k++ {
+				u += w[k-i0] * row[min(max(k, 0), m.x-1)]
+			}
+			r.val[j*x+i] = u / t
+		}
+	}
+	return r
+}
+
+// Resize channels into x*y elements, along x then along y
+func resizeChannels(cs []General[float64], x, y int, interp Interpolation) []General[float64] {
+	r := make([]General[float64], len(cs))
+	for k, c := range cs {
+		r[k] = resizeRows(resizeRows(c, x, interp).Trans(), y, interp).Trans()
+	}
+	return r
+}
+
+// Resize a matrix into x*y elements, the borders being replicated.
+// The kernel of the interpolation is stretched when shrinking, against aliasing.
+func Resize[T types.Real](m Matrix[T], x, y int, interp Interpolation) (General[T], error) {
+	m1 := float64Matrix(m)
+	if m1.Empty() {
+		return General[T]{}, ErrEmptyMatrix
+	} else if x <= 0 || y <= 0 {
+		return General[T]{}, &DimensionError{Op: "Resize", Dims: []Index2{{x, y}}, Why: ErrDimensions}
+	}
+	lo, hi := integerRange[T]()
+	return MapMatrix(resizeChannels([]General[float64]{m1}, x, y, interp)[0], func(t float64) T { return saturate(t, lo, hi) }), nil
+}
+
+// Resize an image into x*y pixels as [Resize]
+func ResizeImage(m image.Image, x, y int, interp Interpolation) (*image.RGBA64, error) {
+	if m == nil || m.Bounds().Empty() {
+		return nil, ErrEmptyMatrix
+	} else if x <= 0 || y <= 0 {
+		return nil, &DimensionError{Op: "ResizeImage", Dims: []Index2{{x, y}}, Why: ErrDimensions}
+	}
+	return channelsImage(resizeChannels(imageChannels(m), x, y, interp), image.Rect(0, 0, x, y)), nil
+}
+
+// Homography rotating a rectangle of x*y by angle about its center, into the bounding
+// rectangle of the rotated one, of dimensions (rx,ry)
+func rotation(x, y int, angle float64) (h General[float64], rx, ry int) {
+	s, c := math.Sincos(angle)
+	// Against rounding up the dimensions by rounding errors
+	const eps = 1e-9
+	rx = int(math.Ceil(math.Abs(c)*float64(x) + math.Abs(s)*float64(y) - eps))
+	ry = int(math.Ceil(math.Abs(s)*float64(x) + math.Abs(c)*float64(y) - eps))
+	h = Rotation(angle)
+	t0, t1 := Translation(-float64(x)/2, -float64(y)/2), Translation(float64(rx)/2, float64(ry)/2)
+	h1, _ := MulMat(h, t0)
+	h2, _ := MulMat(t1, *h1)
+	return *h2, rx, ry
+}
+
+// Rotate a matrix by angle in radians about its center, counterclockwise as displayed,
+// into the rectangle bounding it, the corners being background.
+// Multiples of 90° take the fast path of [Rotate90].
+func Rotate[T types.Real](m Matrix[T], angle float64, interp Interpolation, background T) (General[T], error) {
+	if q := angle / (math.Pi / 2); q == math.Round(q) && !math.IsInf(q, 0) {
+		r := Rotate90(m, int(math.Mod(q, 4)))
+		if r.Empty() {
+			return r, ErrEmptyMatrix
+		}
+		return r, nil
+	}
+	d := m.Dims()
+	h, x, y := rotation(d[0], d[1], angle)
+	return Warp(m, h, x, y, interp, background)
+}
+
+// Rotate an image as [Rotate], into an image of the origin at (0,0)
+func RotateImage(m image.Image, angle float64, interp Interpolation, background color.Color) (*image.RGBA64, error) {
+	if m == nil || m.Bounds().Empty() {
+		return nil, ErrEmptyMatrix
+	}
+	if q := angle / (math.Pi / 2); q == math.Round(q) && !math.IsInf(q, 0) {
+		return RotateImage90(m, int(math.Mod(q, 4))), nil
+	}
+	b := m.Bounds()
+	h, x, y := rotation(b.Dx(), b.Dy(), angle)
+	h1, _ := MulMat(h, Translation(-float64(b.Min.X), -float64(b.Min.Y)))
+	return WarpImage(m, *h1, image.Rect(0, 0, x, y), interp, background)
+}
+
+// Source of the element (i,j) of a matrix of x*y elements rotated by k quarter turns
+// counterclockwise as displayed, and the dimensions of the rotated one
+func rotate90(x, y, k int) (rx, ry int, f func(i, j int) (int, int)) {
+	switch k & 3 {
+	case 1:
+		return y, x, func(i, j int) (int, int) { return x - 1 - j, i }
+	case 2:
+		return x, y, func(i, j int) (int, int) { return x - 1 - i, y - 1 - j }
+	case 3:
+		return y, x, func(i, j int) (int, int) { return j, y - 1 - i }
+	}
+	return x, y, func(i, j int) (int, int) { return i, j }
+}
+
+// Rotate a matrix by k quarter turns counterclockwise as displayed, exactly
+func Rotate90[T types.Number](m Matrix[T], k int) General[T] {
+	v := ViewOf(m)
+	x, y, f := rotate90(v.x, v.y, k)
+	r := NewGeneral[T](x, y)
+	for n := range r.val {
+		r.val[n] = v.at(f(n%x, n/x))
+	}
+	return r
+}
+
+// Rotate an image as [Rotate90], into an image of the origin at (0,0)
+func RotateImage90(m image.Image, k int) *image.RGBA64 {
+	b := m.Bounds()
+	x, y, f := rotate90(b.Dx(), b.Dy(), k)
+	r := image.NewRGBA64(image.Rect(0, 0, x, y))
+	for p := range x * y {
+		i, j := f(p%x, p/x)
+		r.SetRGBA64(p%x, p/x, Pixel(m, b.Min.X+i, b.Min.Y+j))
+	}
+	return r
+}
+
+// Flip a matrix, horizontally reversing the rows and vertically the columns
+func Flip[T types.Number](m Matrix[T], horizontal, vertical bool) General[T] {
+	v := ViewOf(m)
+	r := NewGeneral[T](v.x, v.y)
+	for n := range r.val {
+		i, j := n%v.x, n/v.x
+		if horizontal {
+			i = v.x - 1 - i
+		}
+		if vertical {
+			j = v.y - 1 - j
+		}
+		r.val[n] = v.at(i, j)
+	}
+	return r
+}
+
+// Flip an image as [Flip], keeping its bounds
+func FlipImage(m image.Image, horizontal, vertical bool) *image.RGBA64 {
+	b := m.Bounds()
+	r := image.NewRGBA64(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i, j := x, y
+			if horizontal {
+				i = b.Min.X + b.Max.X - 1 - x
+			}
+			if vertical {
+				j = b.Min.Y + b.Max.Y - 1 - y
+			}
+			r.SetRGBA64(x, y, Pixel(m, i, j))
+		}
+	}
+	return r
+}
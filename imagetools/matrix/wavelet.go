@@ -0,0 +1,354 @@
+package matrix
+
+import (
+	types "imagetools/types"
+	"math"
+	"math/cmplx"
+)
+
+// Two-channel filter bank of a discrete wavelet transform.
+// The four filters have the same even length, padded with zeros if necessary.
+type Wavelet struct {
+	Name         string
+	DecLo, DecHi []float64 // analysis filters
+	RecLo, RecHi []float64 // synthesis filters
+}
+
+// Orthogonal wavelet of the scaling filter h, whose other filters are its mirrors
+func orthogonalWavelet(name string, h []float64) Wavelet {
+	n := len(h)
+	w := Wavelet{
+		Name:  name,
+		DecLo: make([]float64, n),
+		DecHi: make([]float64, n),
+		RecLo: h,
+		RecHi: make([]float64, n),
+	}
+	for k, t := range h {
+		w.DecLo[n-1-k] = t
+		if k%2 == 0 {
+			w.RecHi[n-1-k] = t
+		} else {
+			w.RecHi[n-1-k] = -t
+		}
+	}
+	for k, t := range w.RecHi {
+		w.DecHi[n-1-k] = t
+	}
+	return w
+}
+
+// Haar wavelet, the Daubechies wavelet of 1 vanishing moment
+var Haar = orthogonalWavelet("haar", []float64{math.Sqrt2 / 2, math.Sqrt2 / 2})
+
+// Cohen–Daubechies–Feauveau 9/7 biorthogonal wavelet of JPEG 2000
+var CDF97 = Wavelet{
+	Name: "cdf9/7",
+	DecLo: []float64{0, 0.03782845550726404, -0.023849465019556843, -0.11062440441843718, 0.37740285561283066,
+		0.8526986790088938, 0.37740285561283066, -0.11062440441843718, -0.023849465019556843, 0.03782845550726404},
+	DecHi: []float64{0, -0.06453888262869706, 0.04068941760916406, 0.41809227322161724, -0.7884856164055829,
+		0.41809227322161724, 0.04068941760916406, -0.06453888262869706, 0, 0},
+	RecLo: []float64{0, -0.06453888262869706, -0.04068941760916406, 0.41809227322161724, 0.7884856164055829,
+		0.41809227322161724, -0.04068941760916406, -0.06453888262869706, 0, 0},
+	RecHi: []float64{0, -0.03782845550726404, -0.023849465019556843, 0.11062440441843718, 0.37740285561283066,
+		-0.8526986790088938, 0.37740285561283066, 0.11062440441843718, -0.023849465019556843, -0.03782845550726404},
+}
+
+// Daubechies wavelet of n vanishing moments and filters of length 2n, n in [1,20].
+//
+// The scaling filter is found by the spectral factorization of the
+// Daubechies polynomial, whose roots are the eigenvalues of its companion matrix,
+// keeping the minimum phase roots.
+func Daubechies(n int) (Wavelet, error) {
+	if n < 1 || n > 20 {
+		return Wavelet{}, ErrOutOfBounds
+	}
+	// (1+z)^n
+	h := []complex128{1}
+	for range n {
+		h = polyMul(h, []complex128{1, 1})
+	}
+	if n > 1 {
+		// Companion matrix of P(y) = Σ C(n-1+k,k) y^k, k < n, made monic
+		d := n - 1
+		p := make([]float64, n)
+		for k := range p {
+			p[k] = binomial(n-1+k, k)
+		}
+		c := NewGeneral[float64](d, d)
+		for k := range d {
+			c.val[d-1-k] = -p[k] / p[d]
+			if k > 0 {
+				c.val[k*d+k-1] = 1
+			}
+		}
+		roots, err := Eigen(c)
+		if err != nil {
+			return Wavelet{}, err
+		}
+		// y = (2-z-1/z)/4, of roots z and 1/z, of which that out of the unit circle
+		for _, y := range roots.val {
+			b := 1 - 2*y
+			z := b + cmplx.Sqrt(b*b-1)
+			if cmplx.Abs(z) < 1 {
+				z = 1 / z
+			}
+			h = polyMul(h, []complex128{-z, 1})
+		}
+	}
+	f, s := make([]float64, len(h)), 0.0
+	for i, t := range h {
+		f[i] = real(t)
+		s += f[i]
+	}
+	for i := range f {
+		f[i] *= math.Sqrt2 / s
+	}
+	return orthogonalWavelet("db"+types.FormatNumber(n, 10, 0), f), nil
+}
+
+// Product of polynomials of coefficients in ascending powers
+func polyMul(a, b []complex128) []complex128 {
+	r := make([]complex128, len(a)+len(b)-1)
+	for i, s := range a {
+		for j, t := range b {
+			r[i+j] += s * t
+		}
+	}
+	return r
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := range k {
+		r = r * float64(n-i) / float64(i+1)
+	}
+	return r
+}
+
+// Check the filter lengths of a wavelet
+func (w Wavelet) check(op string) error {
+	n := len(w.DecLo)
+	if n == 0 || n%2 != 0 || len(w.DecHi) != n || len(w.RecLo) != n || len(w.RecHi) != n {
+		return &DimensionError{
+			Op:   op,
+			Dims: []Index2{{len(w.DecLo), len(w.DecHi)}, {len(w.RecLo), len(w.RecHi)}},
+			Why:  ErrDimensions,
+		}
+	}
+	return nil
+}
+
+// Filter each row of m by f and downsample by 2, with the rows extended symmetrically,
+// into (x+len(f)-1)/2 coefficients
+func dwtRows(m View[float64], f []float64) General[float64] {
+	b := Border[float64]{Mode: PadReflect}
+	r := NewGeneral[float64]((m.x+len(f)-1)/2, m.y)
+	buf := make([]float64, m.x)
+	for y := range m.y {
+		src, dst := m.line(0, y, m.x, false, buf), r.val[y*r.x:(y+1)*r.x]
+		for o := range dst {
+			s := 0.0
+			for j, c := range f {
+				s += c * src[b.source(2*o+1-j, m.x)]
+			}
+			dst[o] = s
+		}
+	}
+	return r
+}
+
+// Upsample each row of the coefficients lo and hi by 2, filter by the synthesis filters
+// and keep the part unaffected by the extension, of length 2*x-len(f)+2
+func idwtRows(lo, hi View[float64], w Wavelet) General[float64] {
+	f := len(w.RecLo) / 2
+	r := NewGeneral[float64](2*(lo.x-f+1), lo.y)
+	bufA, bufD := make([]float64, lo.x), make([]float64, hi.x)
+	for y := range lo.y {
+		a, d := lo.line(0, y, lo.x, false, bufA), hi.line(0, y, hi.x, false, bufD)
+		dst := r.val[y*r.x : (y+1)*r.x]
+		for i := f - 1; i < lo.x; i++ {
+			even, odd := 0.0, 0.0
+			for j := range f {
+				even += w.RecLo[2*j]*a[i-j] + w.RecHi[2*j]*d[i-j]
+				odd += w.RecLo[2*j+1]*a[i-j] + w.RecHi[2*j+1]*d[i-j]
+			}
+			o := 2 * (i - f + 1)
+			dst[o], dst[o+1] = even, odd
+		}
+	}
+	return r
+}
+
+// Crop the reconstruction of rows of length x
+func cropRows(op string, m General[float64], x int) (General[float64], error) {
+	if m.x < x || x <= 0 {
+		return General[float64]{}, &DimensionError{
+			Op:   op,
+			Dims: []Index2{m.Dims(), {x, m.y}},
+			Why:  ErrDimensions,
+		}
+	}
+	return m.Pad(0, 0, x-m.x, 0, Border[float64]{}), nil
+}
+
+// Single-level 1-D DWT of each row of a matrix, into the approximation and detail coefficients
+func DWT(m Matrix[float64], w Wavelet) (lo, hi General[float64], err error) {
+	if err = w.check("DWT"); err != nil {
+		return
+	}
+	v := ViewOf(m)
+	if v.Empty() {
+		return lo, hi, ErrEmptyMatrix
+	}
+	return dwtRows(v, w.DecLo), dwtRows(v, w.DecHi), nil
+}
+
+// Single-level 1-D inverse DWT of each row of the coefficients, into rows of length x
+func IDWT(lo, hi Matrix[float64], w Wavelet, x int) (General[float64], error) {
+	if err := w.check("IDWT"); err != nil {
+		return General[float64]{}, err
+	}
+	l, h := ViewOf(lo), ViewOf(hi)
+	if l.Dims() != h.Dims() || l.x < len(w.RecLo)/2 {
+		return General[float64]{}, &DimensionError{
+			Op:   "IDWT",
+			Dims: []Index2{l.Dims(), h.Dims()},
+			Why:  ErrDimensions,
+		}
+	}
+	return cropRows("IDWT", idwtRows(l, h, w), x)
+}
+
+// Sub-bands of a single-level 2-D DWT.
+// The first letter is the filter along x, the second along y,
+// so LH holds horizontal edges and HL vertical ones.
+type Subbands struct {
+	LL, LH, HL, HH General[float64]
+}
+
+// Single-level 2-D DWT
+func DWT2(m Matrix[float64], w Wavelet) (Subbands, error) {
+	l, h, err := DWT(m, w)
+	if err != nil {
+		return Subbands{}, err
+	}
+	lt, ht := l.View().Trans(), h.View().Trans()
+	return Subbands{
+		LL: dwtRows(lt, w.DecLo).Trans(),
+		LH: dwtRows(lt, w.DecHi).Trans(),
+		HL: dwtRows(ht, w.DecLo).Trans(),
+		HH: dwtRows(ht, w.DecHi).Trans(),
+	}, nil
+}
+
+// Single-level 2-D inverse DWT, into a matrix of dimensions d
+func IDWT2(s Subbands, w Wavelet, d Index2) (General[float64], error) {
+	if err := w.check("IDWT2"); err != nil {
+		return General[float64]{}, err
+	}
+	e := s.LL.Dims()
+	if s.LH.Dims() != e || s.HL.Dims() != e || s.HH.Dims() != e || min(e[0], e[1]) < len(w.RecLo)/2 {
+		return General[float64]{}, &DimensionError{
+			Op:   "IDWT2",
+			Dims: []Index2{e, s.LH.Dims(), s.HL.Dims(), s.HH.Dims()},
+			Why:  ErrDimensions,
+		}
+	}
+	l, err := cropRows("IDWT2", idwtRows(s.LL.View().Trans(), s.LH.View().Trans(), w), d[1])
+	if err != nil {
+		return General[float64]{}, err
+	}
+	h, _ := cropRows("IDWT2", idwtRows(s.HL.View().Trans(), s.HH.View().Trans(), w), d[1])
+	return cropRows("IDWT2", idwtRows(l.View().Trans(), h.View().Trans(), w), d[0])
+}
+
+// Multi-level 2-D DWT, of the detail sub-bands of each level from the finest,
+// and the approximation of the coarsest level
+type Pyramid struct {
+	Wavelet Wavelet
+	LL      General[float64]
+	Levels  []Subbands // of LL left empty
+	Dims    []Index2   // dimensions of the input of each level
+}
+
+// Maximum number of levels of the DWT of length n,
+// beyond which the coefficients are dominated by the extension
+func DWTMaxLevel(n int, w Wavelet) int {
+	f, l := len(w.DecLo), 0
+	for f > 1 && n >= f-1 && n/(f-1) >= 2 {
+		n /= 2
+		l++
+	}
+	return l
+}
+
+// Multi-level 2-D DWT of the given number of levels,
+// limited by [DWTMaxLevel] of the shorter side, and non-positive for the limit.
+// Symmetric extension makes the transform exact for any dimensions.
+func NewPyramid(m Matrix[float64], w Wavelet, levels int) (Pyramid, error) {
+	if err := w.check("NewPyramid"); err != nil {
+		return Pyramid{}, err
+	}
+	v := ViewOf(m)
+	if v.Empty() {
+		return Pyramid{}, ErrEmptyMatrix
+	}
+	if n := DWTMaxLevel(min(v.x, v.y), w); levels <= 0 || levels > n {
+		levels = max(n, 1)
+	}
+	p := Pyramid{Wavelet: w}
+	var ll Matrix[float64] = v
+	for range levels {
+		s, err := DWT2(ll, w)
+		if err != nil {
+			return Pyramid{}, err
+		}
+		p.Dims = append(p.Dims, ll.Dims())
+		p.LL, s.LL = s.LL, General[float64]{}
+		ll = p.LL
+		p.Levels = append(p.Levels, s)
+	}
+	return p, nil
+}
+
+// Reconstruct the matrix from its pyramid
+func (p Pyramid) Reconstruct() (General[float64], error) {
+	if len(p.Levels) != len(p.Dims) {
+		return General[float64]{}, ErrDimensions
+	}
+	ll := p.LL
+	for i := len(p.Levels) - 1; i >= 0; i-- {
+		s := p.Levels[i]
+		s.LL = ll
+		var err error
+		if ll, err = IDWT2(s, p.Wavelet, p.Dims[i]); err != nil {
+			return General[float64]{}, err
+		}
+	}
+	return ll, nil
+}
+
+// Threshold the detail coefficients in place, for wavelet shrinkage denoising.
+// Soft thresholding shrinks the remaining coefficients toward 0 by t,
+// while hard thresholding keeps them.
+func (p Pyramid) Threshold(t float64, soft bool) {
+	f := func(c float64) float64 {
+		switch {
+		case math.Abs(c) <= t:
+			return 0
+		case !soft:
+			return c
+		case c > 0:
+			return c - t
+		}
+		return c + t
+	}
+	for _, s := range p.Levels {
+		for _, b := range [...]General[float64]{s.LH, s.HL, s.HH} {
+			for i, c := range b.val {
+				b.val[i] = f(c)
+			}
+		}
+	}
+}
@@ -0,0 +1,572 @@
+package imagetools
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// Algorithm of color quantization
+type QuantizeMethod int
+
+const (
+	QuantMedianCut QuantizeMethod = iota // splitting the box of the largest error at the median of its widest channel
+	QuantOctree                          // merging the least populated nodes of the octree of RGB
+	QuantWu                              // Wu's greedy variance minimization over the 32³ histogram of RGB
+	QuantKMeans                          // Lloyd's k-means from k-means++ seeding
+)
+
+// Options of quantization
+type QuantizeOptions struct {
+	Method     QuantizeMethod
+	Seed       int64         // of the k-means++ seeding
+	Iterations int           // limit of the k-means iterations, 100 if non-positive
+	Tolerance  float64       // k-means stops once no center moves farther, in 16-bit units
+	Metric     ColorDistance // of the assignment of the pixels, the k-d tree of RGBA if nil
+}
+
+// A color of 16-bit premultiplied RGBA of its number of pixels
+type colorCount struct {
+	c [4]float64
+	n float64
+}
+
+func rgba64Vector(c color.RGBA64) [4]float64 {
+	return [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+}
+
+// Color of the mean of n pixels of the sum s, of the colors clamped to the alpha
+func meanColor(s [4]float64, n float64) color.RGBA64 {
+	a := min(max(math.Round(s[3]/n), 0), 0xffff)
+	c := func(t float64) uint16 { return uint16(min(max(math.Round(t/n), 0), a)) }
+	return color.RGBA64{c(s[0]), c(s[1]), c(s[2]), uint16(a)}
+}
+
+// Distinct colors of an image with their numbers of pixels, in the order of [CompareColors]
+func imageColors(m image.Image) []colorCount {
+	b := m.Bounds()
+	h := map[color.RGBA64]float64{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			h[Pixel(m, x, y)]++
+		}
+	}
+	keys := make([]color.RGBA64, 0, len(h))
+	for c := range h {
+		keys = append(keys, c)
+	}
+	slices.SortFunc(keys, func(a, b color.RGBA64) int { return CompareColors(a, b) })
+	cs := make([]colorCount, len(keys))
+	for i, c := range keys {
+		cs[i] = colorCount{rgba64Vector(c), h[c]}
+	}
+	return cs
+}
+
+// Palette of at most n colors of an image by the method of the options,
+// the same for the same image and options
+func QuantizePalette(m image.Image, n int, o QuantizeOptions) color.Palette {
+	cs := imageColors(m)
+	if len(cs) == 0 || n <= 0 {
+		return color.Palette{}
+	}
+	switch o.Method {
+	case QuantOctree:
+		return octree(cs, n)
+	case QuantWu:
+		return wu(cs, n)
+	case QuantKMeans:
+		return kmeans(cs, n, o)
+	}
+	return medianCut(cs, n)
+}
+
+// Paletted image of at most n colors, up to 256, quantized by [QuantizePalette],
+// of the pixels assigned to the closest colors by [PaletteIndex] of the metric of the options,
+// or by [PaletteTree] if nil
+func QuantizeImage(m image.Image, n int, o QuantizeOptions) *image.Paletted {
+	b := m.Bounds()
+	if b.Empty() || n <= 0 {
+		return new(image.Paletted)
+	}
+	p := QuantizePalette(m, min(n, 256), o)
+	res := image.NewPaletted(b, p)
+	index := func(c color.Color) int { return PaletteIndex(p, c, o.Metric) }
+	if o.Metric == nil {
+		index = NewPaletteTree(p).Index
+	}
+	cache := map[color.RGBA64]uint8{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := Pixel(m, x, y)
+			i, ok := cache[c]
+			if !ok {
+				i = uint8(index(c))
+				cache[c] = i
+			}
+			res.Pix[res.PixOffset(x, y)] = i
+		}
+	}
+	return res
+}
+
+// Median cut of the colors into n boxes, splitting the box of the largest squared error
+// at the weighted median of the channel of the largest variance
+func medianCut(cs []colorCount, n int) color.Palette {
+	type box struct {
+		cs   []colorCount
+		sum  [4]float64
+		n    float64
+		sse  float64
+		vars [4]float64
+	}
+	newBox := func(cs []colorCount) box {
+		b := box{cs: cs}
+		var sq [4]float64
+		for _, c := range cs {
+			for k, t := range c.c {
+				b.sum[k] += c.n * t
+				sq[k] += c.n * t * t
+			}
+			b.n += c.n
+		}
+		for k := range sq {
+			b.vars[k] = sq[k] - b.sum[k]*b.sum[k]/b.n
+			b.sse += b.vars[k]
+		}
+		return b
+	}
+	boxes := []box{newBox(cs)}
+	for len(boxes) < n {
+		i := -1
+		for j, b := range boxes {
+			if len(b.cs) > 1 && (i < 0 || b.sse > boxes[i].sse) {
+				i = j
+			}
+		}
+		if i < 0 {
+			break
+		}
+		b := boxes[i]
+		k := 0
+		for j, v := range b.vars {
+			if v > b.vars[k] {
+				k = j
+			}
+		}
+		slices.SortStableFunc(b.cs, func(x, y colorCount) int {
+			switch {
+			case x.c[k] < y.c[k]:
+				return -1
+			case x.c[k] > y.c[k]:
+				return 1
+			}
+			return 0
+		})
+		s, m := 0.0, len(b.cs)-1
+		for j, c := range b.cs[:len(b.cs)-1] {
+			if s += c.n; s >= b.n/2 {
+				m = j + 1
+				break
+			}
+		}
+		boxes[i] = newBox(b.cs[:m])
+		boxes = append(boxes, newBox(b.cs[m:]))
+	}
+	p := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		p[i] = meanColor(b.sum, b.n)
+	}
+	return p
+}
+
+// Node of the octree of RGB, of the sums of the colors and the number of pixels below
+type octNode struct {
+	children [8]*octNode
+	sum      [4]float64
+	n        float64
+	leaf     bool
+	listed   bool // in the nodes of its level
+}
+
+// Octree quantization of the colors into at most n leaves, fewer only of fewer distinct
+// high bytes of RGB. The tree of depth 8 over them is reduced from the deepest level,
+// merging the children of the nodes of the fewest pixels first, and of the last node
+// only the children of the fewest pixels down to n, the alpha being averaged in the leaves.
+func octree(cs []colorCount, n int) color.Palette {
+	const depth = 8
+	root := new(octNode)
+	var levels [depth][]*octNode // nodes of children at each level, in the order of creation
+	leaves := 0
+	for _, c := range cs {
+		r, g, b := uint16(c.c[0])>>8, uint16(c.c[1])>>8, uint16(c.c[2])>>8
+		node := root
+		for l := range depth {
+			for k := range c.c {
+				node.sum[k] += c.n * c.c[k]
+			}
+			node.n += c.n
+			s := 7 - l
+			i := (r>>s&1)<<2 | (g>>s&1)<<1 | b>>s&1
+			if node.children[i] == nil {
+				node.children[i] = new(octNode)
+				if l == depth-1 {
+					node.children[i].leaf = true
+					leaves++
+				}
+				if !node.listed {
+					node.listed = true
+					levels[l] = append(levels[l], node)
+				}
+			}
+			node = node.children[i]
+		}
+		for k := range c.c {
+			node.sum[k] += c.n * c.c[k]
+		}
+		node.n += c.n
+	}
+	for l := depth - 1; l >= 0 && leaves > n; l-- {
+		// Of the fewest pixels first, the ties in the order of creation
+		slices.SortStableFunc(levels[l], func(a, b *octNode) int {
+			switch {
+			case a.n < b.n:
+				return -1
+			case a.n > b.n:
+				return 1
+			}
+			return 0
+		})
+		for _, node := range levels[l] {
+			if leaves <= n {
+				break
+			}
+			var children []*octNode
+			for _, c := range node.children {
+				if c != nil {
+					children = append(children, c)
+				}
+			}
+			if leaves-len(children)+1 >= n {
+				node.children = [8]*octNode{}
+				node.leaf = true
+				leaves -= len(children) - 1
+				continue
+			}
+			// Merging all of them would fall short of n
+			slices.SortStableFunc(children, func(a, b *octNode) int { return cmp.Compare(a.n, b.n) })
+			for _, c := range children[:leaves-n] {
+				for k := range c.sum {
+					children[leaves-n].sum[k] += c.sum[k]
+				}
+				children[leaves-n].n += c.n
+				for i := range node.children {
+					if node.children[i] == c {
+						node.children[i] = nil
+					}
+				}
+			}
+			leaves = n
+		}
+	}
+	var p color.Palette
+	var walk func(*octNode)
+	walk = func(node *octNode) {
+		if node.leaf {
+			p = append(p, meanColor(node.sum, node.n))
+			return
+		}
+		for _, c := range node.children {
+			if c != nil {
+				walk(c)
+			}
+		}
+	}
+	walk(root)
+	return p
+}
+
+// Wu's quantization of the colors into at most n boxes of the histogram of 5 bits of RGB,
+// cutting the box of the largest variance where the variance of the halves is the least,
+// by way of the cumulative moments of the histogram. The alpha is averaged in the boxes.
+func wu(cs []colorCount, n int) color.Palette {
+	const side = 33 // 32 bins and a leading 0 of the cumulative moments
+	at := func(r, g, b int) int { return (r*side+g)*side + b }
+	// Moments of the number of pixels, R, G, B, A and R²+G²+B²
+	var ms [6][]float64
+	for i := range ms {
+		ms[i] = make([]float64, side*side*side)
+	}
+	for _, c := range cs {
+		i := at(int(c.c[0])>>11+1, int(c.c[1])>>11+1, int(c.c[2])>>11+1)
+		ms[0][i] += c.n
+		for k := range 4 {
+			ms[k+1][i] += c.n * c.c[k]
+		}
+		ms[5][i] += c.n * (c.c[0]*c.c[0] + c.c[1]*c.c[1] + c.c[2]*c.c[2])
+	}
+	for _, m := range ms {
+		for r := 1; r < side; r++ {
+			for g := 1; g < side; g++ {
+				for b := 1; b < side; b++ {
+					m[at(r, g, b)] += m[at(r-1, g, b)] + m[at(r, g-1, b)] + m[at(r, g, b-1)] -
+						m[at(r-1, g-1, b)] - m[at(r-1, g, b-1)] - m[at(r, g-1, b-1)] + m[at(r-1, g-1, b-1)]
+				}
+			}
+		}
+	}
+	// Box of the bins (lo,hi] along R, G and B
+	type box struct{ lo, hi [3]int }
+	vol := func(x box, m []float64) float64 {
+		r0, g0, b0, r1, g1, b1 := x.lo[0], x.lo[1], x.lo[2], x.hi[0], x.hi[1], x.hi[2]
+		return m[at(r1, g1, b1)] - m[at(r1, g1, b0)] - m[at(r1, g0, b1)] + m[at(r1, g0, b0)] -
+			m[at(r0, g1, b1)] + m[at(r0, g1, b0)] + m[at(r0, g0, b1)] - m[at(r0, g0, b0)]
+	}
+	// Sum of the squared means weighted, which the variance decreases with
+	score := func(x box) float64 {
+		w := vol(x, ms[0])
+		if w == 0 {
+			return math.Inf(-1)
+		}
+		r, g, b := vol(x, ms[1]), vol(x, ms[2]), vol(x, ms[3])
+		return (r*r + g*g + b*b) / w
+	}
+	variance := func(x box) float64 {
+		if vol(x, ms[0]) == 0 {
+			return 0
+		}
+		return vol(x, ms[5]) - score(x)
+	}
+	cut := func(x box) (box, box, bool) {
+		best, bx, by := math.Inf(-1), box{}, box{}
+		for d := range 3 {
+			for i := x.lo[d] + 1; i < x.hi[d]; i++ {
+				a, b := x, x
+				a.hi[d], b.lo[d] = i, i
+				if s := score(a) + score(b); s > best {
+					best, bx, by = s, a, b
+				}
+			}
+		}
+		return bx, by, !math.IsInf(best, -1)
+	}
+	boxes := []box{{hi: [3]int{side - 1, side - 1, side - 1}}}
+	vars := []float64{variance(boxes[0])}
+	for len(boxes) < n {
+		i := 0
+		for j, v := range vars {
+			if v > vars[i] {
+				i = j
+			}
+		}
+		if vars[i] <= 0 {
+			break
+		}
+		a, b, ok := cut(boxes[i])
+		if !ok {
+			vars[i] = 0
+			continue
+		}
+		boxes[i], vars[i] = a, variance(a)
+		boxes, vars = append(boxes, b), append(vars, variance(b))
+	}
+	var p color.Palette
+	for _, x := range boxes {
+		if w := vol(x, ms[0]); w > 0 {
+			p = append(p, meanColor([4]float64{vol(x, ms[1]), vol(x, ms[2]), vol(x, ms[3]), vol(x, ms[4])}, w))
+		}
+	}
+	return p
+}
+
+// Squared Euclidean distance of colors
+func sqDist(x, y [4]float64) float64 {
+	s := 0.0
+	for k := range x {
+		s += (x[k] - y[k]) * (x[k] - y[k])
+	}
+	return s
+}
+
+// k-means of the colors into n clusters, seeded by k-means++ of the seed of the options,
+// iterated until no center moves farther than the tolerance, or up to the iteration limit
+func kmeans(cs []colorCount, n int, o QuantizeOptions) color.Palette {
+	rng := rand.New(rand.NewSource(o.Seed))
+	// k-means++: each center drawn with the probability of the squared distance
+	// to the nearest center chosen, weighted by the pixels
+	pick := func(w func(i int) float64) int {
+		s := 0.0
+		for i := range cs {
+			s += w(i)
+		}
+		if s <= 0 {
+			return -1
+		}
+		t := rng.Float64() * s
+		for i := range cs {
+			if t -= w(i); t < 0 {
+				return i
+			}
+		}
+		return len(cs) - 1
+	}
+	centers := [][4]float64{cs[pick(func(i int) float64 { return cs[i].n })].c}
+	d := make([]float64, len(cs))
+	for i, c := range cs {
+		d[i] = sqDist(c.c, centers[0])
+	}
+	for len(centers) < n {
+		i := pick(func(i int) float64 { return cs[i].n * d[i] })
+		if i < 0 {
+			break
+		}
+		centers = append(centers, cs[i].c)
+		for j, c := range cs {
+			d[j] = min(d[j], sqDist(c.c, cs[i].c))
+		}
+	}
+
+	iterations := o.Iterations
+	if iterations <= 0 {
+		iterations = 100
+	}
+	p := make(color.Palette, len(centers))
+	for range iterations {
+		for i, c := range centers {
+			p[i] = meanColor(c, 1)
+		}
+		index := func(c colorCount) int { return PaletteIndex(p, meanColor(c.c, 1), o.Metric) }
+		if o.Metric == nil {
+			t := newKDTree(centers)
+			index = func(c colorCount) int { return t.nearest(c.c) }
+		}
+		sums, ns := make([][4]float64, len(centers)), make([]float64, len(centers))
+		for _, c := range cs {
+			i := index(c)
+			for k := range c.c {
+				sums[i][k] += c.n * c.c[k]
+			}
+			ns[i] += c.n
+		}
+		shift := 0.0
+		for i := range centers {
+			if ns[i] == 0 {
+				continue
+			}
+			var m [4]float64
+			for k := range m {
+				m[k] = sums[i][k] / ns[i]
+			}
+			shift = max(shift, sqDist(m, centers[i]))
+			centers[i] = m
+		}
+		if math.Sqrt(shift) <= o.Tolerance {
+			break
+		}
+	}
+	for i, c := range centers {
+		p[i] = meanColor(c, 1)
+	}
+	return p
+}
+
+// k-d tree of points of 16-bit RGBA, implicit in a permutation of the points,
+// of the median of each range as the node, split along the axis of the widest spread
+type kdTree struct {
+	pts  [][4]float64
+	idx  []int
+	axis []int
+}
+
+func newKDTree(pts [][4]float64) kdTree {
+	t := kdTree{pts: pts, idx: make([]int, len(pts)), axis: make([]int, len(pts))}
+	for i := range t.idx {
+		t.idx[i] = i
+	}
+	t.build(0, len(pts))
+	return t
+}
+
+func (t kdTree) build(lo, hi int) {
+	if hi-lo <= 0 {
+		return
+	}
+	a, spread := 0, -1.0
+	for k := range 4 {
+		l, h := math.Inf(1), math.Inf(-1)
+		for _, i := range t.idx[lo:hi] {
+			l, h = min(l, t.pts[i][k]), max(h, t.pts[i][k])
+		}
+		if h-l > spread {
+			a, spread = k, h-l
+		}
+	}
+	slices.SortFunc(t.idx[lo:hi], func(i, j int) int {
+		switch {
+		case t.pts[i][a] < t.pts[j][a]:
+			return -1
+		case t.pts[i][a] > t.pts[j][a]:
+			return 1
+		}
+		return i - j
+	})
+	mid := (lo + hi) / 2
+	t.axis[mid] = a
+	t.build(lo, mid)
+	t.build(mid+1, hi)
+}
+
+// Index of the point nearest q, the smallest of the ties
+func (t kdTree) nearest(q [4]float64) int {
+	best, bestD := -1, math.Inf(1)
+	var search func(lo, hi int)
+	search = func(lo, hi int) {
+		if hi-lo <= 0 {
+			return
+		}
+		mid := (lo + hi) / 2
+		i := t.idx[mid]
+		if d := sqDist(q, t.pts[i]); d < bestD || d == bestD && i < best {
+			best, bestD = i, d
+		}
+		diff := q[t.axis[mid]] - t.pts[i][t.axis[mid]]
+		if diff < 0 {
+			search(lo, mid)
+			if diff*diff <= bestD {
+				search(mid+1, hi)
+			}
+		} else {
+			search(mid+1, hi)
+			if diff*diff <= bestD {
+				search(lo, mid)
+			}
+		}
+	}
+	search(0, len(t.idx))
+	return best
+}
+
+// k-d tree of the colors of a palette, for the lookup of the nearest color
+// in O(log n) on average, as by [color.Palette.Index] of the squared Euclidean distance
+// in 16-bit RGBA
+type PaletteTree struct {
+	tree kdTree
+}
+
+func NewPaletteTree(p color.Palette) PaletteTree {
+	pts := make([][4]float64, len(p))
+	for i, c := range p {
+		r, g, b, a := c.RGBA()
+		pts[i] = [4]float64{float64(r), float64(g), float64(b), float64(a)}
+	}
+	return PaletteTree{newKDTree(pts)}
+}
+
+// Index of the color of the palette nearest c, the first of the ties, or -1 if empty
+func (t PaletteTree) Index(c color.Color) int {
+	r, g, b, a := c.RGBA()
+	return t.tree.nearest([4]float64{float64(r), float64(g), float64(b), float64(a)})
+}
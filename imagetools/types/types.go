@@ -1,29 +1,29 @@
-package imagetools
-
-type SignedInteger interface {
-	~int | ~int8 | ~int16 | ~int32 | ~int64
-}
-type UnsignedInteger interface {
-	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
-}
-type Integer interface {
-	SignedInteger | UnsignedInteger
-}
-type Float interface {
-	~float32 | ~float64
-}
-type Complex interface {
-	~complex64 | ~complex128
-}
-type Real interface {
-	Integer | Float
-}
-type Number interface {
-	Real | Complex
-}
-
-// Ordered is a constraint that permits any ordered type:
-// any type that supports the operators < <= >= >
-type Ordered interface {
-	Real | ~string
-}
+package imagetools
+
+type SignedInteger interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+type UnsignedInteger interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+type Integer interface {
+	SignedInteger | UnsignedInteger
+}
+type Float interface {
+	~float32 | ~float64
+}
+type Complex interface {
+	~complex64 | ~complex128
+}
+type Real interface {
+	Integer | Float
+}
+type Number interface {
+	Real | Complex
+}
+
+// Ordered is a constraint that permits any ordered type:
+// any type that supports the operators < <= >= >
+type Ordered interface {
+	Real | ~string
+}